@@ -0,0 +1,202 @@
+package bitcoin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidAddress = errors.New("invalid bitcoin address")
+
+type Network string
+
+const (
+	Mainnet Network = "mainnet"
+	Testnet Network = "testnet"
+)
+
+type AddressType string
+
+const (
+	P2PKH   AddressType = "p2pkh"
+	P2SH    AddressType = "p2sh"
+	Segwit  AddressType = "segwit-v0"
+	Taproot AddressType = "taproot"
+	// SegwitFuture covers witness versions 2-16, valid but not yet in use.
+	SegwitFuture AddressType = "segwit"
+)
+
+type Address struct {
+	Text    string
+	Type    AddressType
+	Network Network
+}
+
+// ParseAddress validates a legacy Base58Check address or a Bech32/Bech32m
+// segwit address and reports its type and network.
+func ParseAddress(addr string) (Address, error) {
+	lower := strings.ToLower(addr)
+	if strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "tb1") {
+		return parseSegwit(addr)
+	}
+	return parseBase58(addr)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func parseBase58(addr string) (Address, error) {
+	decoded, err := base58Decode(addr)
+	if err != nil {
+		return Address{}, err
+	}
+	if len(decoded) != 25 {
+		return Address{}, fmt.Errorf("%w: %s decodes to %d bytes", ErrInvalidAddress, addr, len(decoded))
+	}
+	payload, checksum := decoded[:21], decoded[21:]
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	if !bytes.Equal(second[:4], checksum) {
+		return Address{}, fmt.Errorf("%w: %s has a bad checksum", ErrInvalidAddress, addr)
+	}
+	a := Address{Text: addr}
+	switch payload[0] {
+	case 0x00:
+		a.Type, a.Network = P2PKH, Mainnet
+	case 0x05:
+		a.Type, a.Network = P2SH, Mainnet
+	case 0x6f:
+		a.Type, a.Network = P2PKH, Testnet
+	case 0xc4:
+		a.Type, a.Network = P2SH, Testnet
+	default:
+		return Address{}, fmt.Errorf("%w: %s has unknown version byte 0x%02x", ErrInvalidAddress, addr, payload[0])
+	}
+	return a, nil
+}
+
+func base58Decode(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		idx := strings.IndexRune(base58Alphabet, r)
+		if idx < 0 {
+			return nil, fmt.Errorf("%w: %q is not base58", ErrInvalidAddress, r)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(idx)))
+	}
+	decoded := n.Bytes()
+	// Each leading '1' encodes a leading zero byte.
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), decoded...), nil
+}
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	bech32Const   = 1
+	bech32mConst  = 0x2bc830a3
+)
+
+func parseSegwit(addr string) (Address, error) {
+	if addr != strings.ToLower(addr) && addr != strings.ToUpper(addr) {
+		return Address{}, fmt.Errorf("%w: %s mixes upper and lower case", ErrInvalidAddress, addr)
+	}
+	addr = strings.ToLower(addr)
+	sep := strings.LastIndexByte(addr, '1')
+	if sep < 1 || sep+8 > len(addr) || len(addr) > 90 {
+		return Address{}, fmt.Errorf("%w: %s is malformed", ErrInvalidAddress, addr)
+	}
+	hrp, data := addr[:sep], addr[sep+1:]
+	if hrp != "bc" && hrp != "tb" {
+		return Address{}, fmt.Errorf("%w: %s has unknown prefix %q", ErrInvalidAddress, addr, hrp)
+	}
+	values := make([]byte, len(data))
+	for i := range data {
+		idx := strings.IndexByte(bech32Charset, data[i])
+		if idx < 0 {
+			return Address{}, fmt.Errorf("%w: %q is not bech32", ErrInvalidAddress, data[i])
+		}
+		values[i] = byte(idx)
+	}
+	check := bech32Polymod(append(bech32HRPExpand(hrp), values...))
+	if check != bech32Const && check != bech32mConst {
+		return Address{}, fmt.Errorf("%w: %s has a bad checksum", ErrInvalidAddress, addr)
+	}
+
+	version := values[0]
+	program, err := convertBits(values[1:len(values)-6], 5, 8)
+	if err != nil || len(program) < 2 || len(program) > 40 || version > 16 {
+		return Address{}, fmt.Errorf("%w: %s has a bad witness program", ErrInvalidAddress, addr)
+	}
+	// BIP 350: version 0 uses Bech32, every later version Bech32m.
+	if (version == 0) != (check == bech32Const) {
+		return Address{}, fmt.Errorf("%w: %s uses the wrong checksum variant for witness v%d", ErrInvalidAddress, addr, version)
+	}
+	a := Address{Text: addr, Network: Mainnet}
+	if hrp == "tb" {
+		a.Network = Testnet
+	}
+	switch {
+	case version == 0 && (len(program) == 20 || len(program) == 32):
+		a.Type = Segwit
+	case version == 0:
+		return Address{}, fmt.Errorf("%w: %s has a %d-byte v0 program", ErrInvalidAddress, addr, len(program))
+	case version == 1 && len(program) == 32:
+		a.Type = Taproot
+	default:
+		a.Type = SegwitFuture
+	}
+	return a, nil
+}
+
+func bech32Polymod(values []byte) uint32 {
+	generator := [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+	chk := uint32(1)
+	for _, v := range values {
+		top := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+	return chk
+}
+
+func bech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+// convertBits regroups 5-bit values into bytes, rejecting non-zero padding.
+func convertBits(data []byte, from, to uint) ([]byte, error) {
+	var acc, bits uint
+	maxv := uint(1)<<to - 1
+	out := make([]byte, 0, len(data)*int(from)/int(to))
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if bits >= from || (acc<<(to-bits))&maxv != 0 {
+		return nil, ErrInvalidAddress
+	}
+	return out, nil
+}
@@ -1,17 +1,127 @@
 package bitcoin
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+)
+
+const Method = "bitcoin"
+
+// DefaultConfirmations is how many blocks deep a payment must be before it
+// is treated as settled.
+const DefaultConfirmations = 3
 
 type Bitcoin struct {
-    walletAddress string
+	address  Address
+	rates    exchangerate.Provider
+	refunds  *paymentstrategy.RefundBook
+	required int
+	fees     paymentstrategy.FeeModel
+
+	mu       sync.Mutex
+	invoices map[string]*Invoice
+}
+
+// NewBitcoin validates the wallet address up front so a typo is caught
+// before any invoice is raised against it.
+func NewBitcoin(wallet string, rates exchangerate.Provider) (*Bitcoin, error) {
+	address, err := ParseAddress(wallet)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", paymentstrategy.ErrInvalidInstrument, err)
+	}
+	return &Bitcoin{
+		address:  address,
+		rates:    rates,
+		refunds:  paymentstrategy.NewRefundBook(),
+		required: DefaultConfirmations,
+		fees:     DefaultFees,
+		invoices: make(map[string]*Invoice),
+	}, nil
+}
+
+func (b *Bitcoin) Address() Address {
+	return b.address
+}
+
+func (b *Bitcoin) Identify() paymentstrategy.Instrument {
+	return paymentstrategy.Instrument{Method: Method, ID: b.address.Text, Display: b.address.Text}
+}
+
+// SetRequiredConfirmations changes the depth needed for invoices raised
+// from now on.
+func (b *Bitcoin) SetRequiredConfirmations(n int) {
+	if n < 1 {
+		n = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.required = n
+}
+
+// Pay quotes the amount in satoshis and opens an invoice for it. The
+// receipt stays pending until the invoice collects enough confirmations;
+// the outcome is delivered through Receipt.OnSettle.
+func (b *Bitcoin) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	receipt.Instrument = b.address.Text
+	if !amount.IsPositive() {
+		return receipt.Fail(paymentstrategy.ErrInvalidAmount)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	coins, rate, err := exchangerate.Convert(b.rates, amount, money.BTC)
+	if err != nil {
+		return receipt.Fail(err)
+	}
+	if !coins.IsPositive() {
+		return receipt.Fail(fmt.Errorf("%w: %s is below one satoshi", paymentstrategy.ErrInvalidAmount, amount))
+	}
+	receipt.Settle(coins, rate)
+	receipt.Fee = b.fees.Fee(amount)
+	txID := receipt.TransactionID
+	pending := receipt.Defer(func() error { return b.cancel(txID) })
+
+	b.mu.Lock()
+	required := b.required
+	b.invoices[txID] = &Invoice{
+		TransactionID: txID,
+		Address:       b.address.Text,
+		Expected:      Satoshis(coins),
+		Required:      required,
+		State:         InvoicePending,
+		pending:       pending,
+	}
+	b.mu.Unlock()
+
+	fmt.Printf("Invoiced %s (%s) to Bitcoin: %s, awaiting %d confirmation(s)\n", FormatSatoshis(Satoshis(coins)), amount, b.address.Text, required)
+	return receipt, nil
 }
 
-func NewBitcoin(wallet string) *Bitcoin {
-    return &Bitcoin{
-        walletAddress: wallet,
-    }
+// Refund returns coins at the rate the original charge was settled at, so
+// the customer bears no exchange movement in either direction. Only
+// settled invoices can be refunded.
+func (b *Bitcoin) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	refund := paymentstrategy.NewRefundReceipt(original, amount)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return refund.Fail(err)
+	}
+	if inv, ok := b.Invoice(original.TransactionID); ok && inv.State != InvoiceSettled && inv.State != InvoiceOverpaid {
+		return refund.Fail(fmt.Errorf("%w: invoice %s is %s", ErrNotSettled, inv.TransactionID, inv.State))
+	}
+	if err := b.refunds.Reserve(original, amount); err != nil {
+		return refund.Fail(err)
+	}
+	fmt.Printf("Refunded %s (%s) to Bitcoin: %s\n", FormatSatoshis(Satoshis(refund.SettledAmount)), amount, b.address.Text)
+	return refund, nil
 }
 
-func (b *Bitcoin) Pay(amount float64) {
-    fmt.Printf("Paid %.2f using Bitcoin: %s\n", amount, b.walletAddress)
-}
\ No newline at end of file
+// Settlements reports every charge made to this wallet and how much of each
+// has been refunded.
+func (b *Bitcoin) Settlements() []paymentstrategy.Settlement {
+	return b.refunds.Settlements()
+}
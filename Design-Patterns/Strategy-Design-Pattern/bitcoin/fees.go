@@ -0,0 +1,22 @@
+package bitcoin
+
+import (
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// DefaultFees is what on-chain charges cost unless SetFees says
+// otherwise: a flat percentage with a cap, since network fees do not grow
+// with the amount, and a floor below which the payment is dust.
+var DefaultFees = paymentstrategy.FeeModel{
+	{Currency: money.USD, PercentBPS: 100, MaxFee: money.MustParse("15.00", money.USD), MinAmount: money.MustParse("5.00", money.USD)},
+	{Currency: money.EUR, PercentBPS: 100, MaxFee: money.MustParse("15.00", money.EUR), MinAmount: money.MustParse("5.00", money.EUR)},
+}
+
+func (b *Bitcoin) Fees() paymentstrategy.FeeModel {
+	return b.fees
+}
+
+func (b *Bitcoin) SetFees(fees paymentstrategy.FeeModel) {
+	b.fees = fees
+}
@@ -0,0 +1,173 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+var (
+	ErrUnknownInvoice = errors.New("unknown bitcoin invoice")
+	ErrNotSettled     = errors.New("bitcoin invoice not settled")
+	ErrFundsReceived  = errors.New("bitcoin invoice has already received funds")
+	ErrUnderpaid      = fmt.Errorf("%w: bitcoin invoice underpaid", paymentstrategy.ErrInsufficientFunds)
+)
+
+// SatoshisPerBTC is the number of satoshis in one bitcoin.
+const SatoshisPerBTC = 100_000_000
+
+// Satoshis returns a BTC amount in its smallest unit. money.BTC already
+// carries eight minor units, so this is the raw amount.
+func Satoshis(m money.Money) int64 {
+	return m.Amount()
+}
+
+func FromSatoshis(sats int64) money.Money {
+	return money.New(sats, money.BTC)
+}
+
+func FormatSatoshis(sats int64) string {
+	return fmt.Sprintf("%d sat", sats)
+}
+
+type InvoiceState string
+
+const (
+	InvoicePending    InvoiceState = "pending"
+	InvoiceConfirming InvoiceState = "confirming"
+	InvoiceSettled    InvoiceState = "settled"
+	InvoiceUnderpaid  InvoiceState = "underpaid"
+	InvoiceOverpaid   InvoiceState = "overpaid"
+	InvoiceCancelled  InvoiceState = "cancelled"
+)
+
+// Invoice tracks one on-chain payment from broadcast to settlement.
+type Invoice struct {
+	TransactionID string
+	Address       string
+	Expected      int64
+	Received      int64
+	Confirmations int
+	Required      int
+	State         InvoiceState
+
+	pending *paymentstrategy.PendingCharge
+}
+
+// Shortfall is how many satoshis are still owed; negative when the
+// customer sent too much.
+func (i Invoice) Shortfall() int64 {
+	return i.Expected - i.Received
+}
+
+func (i Invoice) Final() bool {
+	switch i.State {
+	case InvoiceSettled, InvoiceUnderpaid, InvoiceOverpaid, InvoiceCancelled:
+		return true
+	}
+	return false
+}
+
+func (b *Bitcoin) Invoice(txID string) (Invoice, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inv, ok := b.invoices[txID]
+	if !ok {
+		return Invoice{}, false
+	}
+	return *inv, true
+}
+
+// Observe records satoshis seen on-chain for an invoice. Several outputs
+// may pay the same invoice, so amounts accumulate.
+func (b *Bitcoin) Observe(txID string, sats int64) (Invoice, error) {
+	if sats <= 0 {
+		return Invoice{}, fmt.Errorf("%w: %s", paymentstrategy.ErrInvalidAmount, FormatSatoshis(sats))
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inv, ok := b.invoices[txID]
+	if !ok {
+		return Invoice{}, fmt.Errorf("%w: %s", ErrUnknownInvoice, txID)
+	}
+	if inv.Final() {
+		return *inv, fmt.Errorf("invoice %s already %s", txID, inv.State)
+	}
+	inv.Received += sats
+	inv.State = InvoiceConfirming
+	return *inv, nil
+}
+
+// Confirm adds one block of depth. Once the invoice reaches its required
+// depth it settles: an exact or larger payment resolves the charge as
+// succeeded, a short one declines it. Holders of the pending receipt get
+// the outcome through Receipt.OnSettle.
+func (b *Bitcoin) Confirm(txID string) (Invoice, error) {
+	inv, settled, err := b.confirm(txID)
+	if err != nil || !settled {
+		return inv, err
+	}
+	// Resolve outside the lock: waiters may call back into the strategy.
+	switch short := inv.Shortfall(); {
+	case short > 0:
+		_, err = inv.pending.Fail(fmt.Errorf("%w: received %s of %s", ErrUnderpaid, FormatSatoshis(inv.Received), FormatSatoshis(inv.Expected)))
+		return inv, err
+	case short < 0:
+		fmt.Printf("Bitcoin invoice %s overpaid by %s\n", txID, FormatSatoshis(-short))
+	}
+	receipt, err := inv.pending.Settle()
+	if err == nil {
+		b.refunds.Record(receipt)
+	}
+	return inv, err
+}
+
+// confirm adds the block and reports whether it was the one that settled
+// the invoice.
+func (b *Bitcoin) confirm(txID string) (Invoice, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inv, ok := b.invoices[txID]
+	if !ok {
+		return Invoice{}, false, fmt.Errorf("%w: %s", ErrUnknownInvoice, txID)
+	}
+	if inv.Final() {
+		return *inv, false, nil
+	}
+	if inv.Received == 0 {
+		return *inv, false, fmt.Errorf("invoice %s has no payment to confirm", txID)
+	}
+	inv.Confirmations++
+	if inv.Confirmations < inv.Required {
+		return *inv, false, nil
+	}
+	switch short := inv.Shortfall(); {
+	case short > 0:
+		inv.State = InvoiceUnderpaid
+	case short < 0:
+		inv.State = InvoiceOverpaid
+	default:
+		inv.State = InvoiceSettled
+	}
+	return *inv, true, nil
+}
+
+// cancel closes an invoice nothing has been paid into yet, so later
+// payments to it are not accepted.
+func (b *Bitcoin) cancel(txID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	inv, ok := b.invoices[txID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownInvoice, txID)
+	}
+	if inv.Final() {
+		return fmt.Errorf("%w: invoice %s is %s", paymentstrategy.ErrAlreadySettled, txID, inv.State)
+	}
+	if inv.Received > 0 {
+		return fmt.Errorf("%w: %s of %s on %s", ErrFundsReceived, FormatSatoshis(inv.Received), FormatSatoshis(inv.Expected), txID)
+	}
+	inv.State = InvoiceCancelled
+	return nil
+}
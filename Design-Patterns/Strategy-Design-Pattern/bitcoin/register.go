@@ -0,0 +1,29 @@
+package bitcoin
+
+import (
+	exchangerate "strategy-design/exchange-rate"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// Spec: bitcoin:wallet=...,rates=rates.json
+func init() {
+	paymentstrategy.Register(Method, func(cfg paymentstrategy.Config) (paymentstrategy.PaymentStrategy, error) {
+		wallet, err := cfg.Require("wallet")
+		if err != nil {
+			return nil, err
+		}
+		path, err := cfg.Require("rates")
+		if err != nil {
+			return nil, err
+		}
+		rates, err := exchangerate.NewFileProvider(path)
+		if err != nil {
+			return nil, err
+		}
+		btc, err := NewBitcoin(wallet, rates)
+		if err != nil {
+			return nil, err
+		}
+		return btc, nil
+	})
+}
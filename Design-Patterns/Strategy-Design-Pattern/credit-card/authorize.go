@@ -0,0 +1,65 @@
+package creditcard
+
+import (
+	"context"
+	"fmt"
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+func (c *CreditCard) Authorizations() *paymentstrategy.AuthorizationBook {
+	return c.auths
+}
+
+// Authorize places a hold on the card. The card must still be valid now;
+// it may expire before capture without affecting the hold.
+func (c *CreditCard) Authorize(ctx context.Context, amount money.Money) (*paymentstrategy.Authorization, error) {
+	if err := validateExpiry(c.expiryMonth, c.expiryYear); err != nil {
+		return nil, paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	if !amount.IsPositive() {
+		return nil, paymentstrategy.AuthorizationFailed(Method, paymentstrategy.ErrInvalidAmount)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return nil, paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	auth := c.auths.Open(Method, c.Masked(), amount, "")
+	fmt.Printf("Authorized %s on Credit Card: %s\n", amount, c)
+	return auth, nil
+}
+
+// Capture settles at the rate in force at capture time, not at
+// authorization, since that is when the card is actually charged.
+func (c *CreditCard) Capture(ctx context.Context, auth *paymentstrategy.Authorization, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	receipt.Instrument = c.Masked()
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	if c.settlement != "" {
+		settled, rate, err := exchangerate.Convert(c.rates, amount, c.settlement)
+		if err != nil {
+			return receipt.Fail(err)
+		}
+		receipt.Settle(settled, rate)
+	}
+	if _, err := c.auths.Capture(auth.ID, amount); err != nil {
+		return receipt.Fail(err)
+	}
+	receipt.Fee = c.fees.Fee(amount)
+	fmt.Printf("Captured %s of %s on Credit Card: %s\n", amount, auth.Amount, c)
+	c.refunds.Record(receipt)
+	return receipt, nil
+}
+
+func (c *CreditCard) Void(ctx context.Context, auth *paymentstrategy.Authorization) error {
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	if _, err := c.auths.Void(auth.ID); err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	fmt.Printf("Voided %s on Credit Card: %s\n", auth.Amount, c)
+	return nil
+}
@@ -1,19 +1,136 @@
 package creditcard
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
 
+const Method = "credit-card"
+
+// CreditCard keeps the full card number only to charge it; everything it
+// prints or returns shows at most the last four digits. The CVV is checked
+// at construction and never stored.
 type CreditCard struct {
-    cardNumber string
-    name       string
+	cardNumber  string
+	name        string
+	network     Network
+	expiryMonth int
+	expiryYear  int
+	settlement  money.Currency
+	rates       exchangerate.Provider
+	refunds     *paymentstrategy.RefundBook
+	auths       *paymentstrategy.AuthorizationBook
+	fees        paymentstrategy.FeeModel
+}
+
+func NewCreditCard(name, cardNumber string, expiryMonth, expiryYear int, cvv string) (*CreditCard, error) {
+	number, err := normalizeNumber(cardNumber)
+	if err != nil {
+		return nil, err
+	}
+	network := DetectNetwork(number)
+	if network == Unknown {
+		return nil, invalid(ErrUnsupportedNetwork)
+	}
+	if err := validateExpiry(expiryMonth, expiryYear); err != nil {
+		return nil, err
+	}
+	if err := validateCVV(cvv, network); err != nil {
+		return nil, err
+	}
+	return &CreditCard{
+		cardNumber:  number,
+		name:        name,
+		network:     network,
+		expiryMonth: expiryMonth,
+		expiryYear:  expiryYear,
+		refunds:     paymentstrategy.NewRefundBook(),
+		auths:       paymentstrategy.NewAuthorizationBook(paymentstrategy.DefaultAuthorizationTTL),
+		fees:        DefaultFees,
+	}, nil
+}
+
+func (c *CreditCard) Network() Network {
+	return c.network
+}
+
+func (c *CreditCard) LastFour() string {
+	return c.cardNumber[len(c.cardNumber)-4:]
+}
+
+func (c *CreditCard) Masked() string {
+	return fmt.Sprintf("%s ****%s", c.network, c.LastFour())
+}
+
+// Identify keys the card by its fingerprint, so rules can match it across
+// charges without any of its digits.
+func (c *CreditCard) Identify() paymentstrategy.Instrument {
+	return paymentstrategy.Instrument{
+		Method:  Method,
+		ID:      fingerprint(c.cardNumber),
+		Display: c.Masked(),
+		Country: c.network.country(),
+	}
+}
+
+func (c *CreditCard) String() string {
+	return fmt.Sprintf("%s (%s)", c.Masked(), c.name)
+}
+
+func (c *CreditCard) GoString() string {
+	return fmt.Sprintf("creditcard.CreditCard{%s}", c.String())
 }
 
-func NewCreditCard(name, cardNumber string) *CreditCard {
-    return &CreditCard{
-        cardNumber: cardNumber,
-        name:       name,
-    }
+// SetSettlementCurrency makes the strategy settle every charge in currency,
+// converting with rates. Without it charges settle in the cart's currency.
+func (c *CreditCard) SetSettlementCurrency(currency money.Currency, rates exchangerate.Provider) {
+	c.settlement = currency
+	c.rates = rates
 }
 
-func (c *CreditCard) Pay(amount float64) {
-    fmt.Printf("Paid %.2f using Credit Card (%s): %s\n", amount, c.name, c.cardNumber)
-}
\ No newline at end of file
+func (c *CreditCard) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	receipt.Instrument = c.Masked()
+	if err := validateExpiry(c.expiryMonth, c.expiryYear); err != nil {
+		return receipt.Fail(err)
+	}
+	if !amount.IsPositive() {
+		return receipt.Fail(paymentstrategy.ErrInvalidAmount)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	if c.settlement != "" {
+		settled, rate, err := exchangerate.Convert(c.rates, amount, c.settlement)
+		if err != nil {
+			return receipt.Fail(err)
+		}
+		receipt.Settle(settled, rate)
+	}
+	receipt.Fee = c.fees.Fee(amount)
+	fmt.Printf("Paid %s using Credit Card: %s\n", amount, c)
+	c.refunds.Record(receipt)
+	return receipt, nil
+}
+
+// Refund credits the card even if it has expired since the charge.
+func (c *CreditCard) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	refund := paymentstrategy.NewRefundReceipt(original, amount)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return refund.Fail(err)
+	}
+	if err := c.refunds.Reserve(original, amount); err != nil {
+		return refund.Fail(err)
+	}
+	fmt.Printf("Refunded %s to Credit Card: %s\n", amount, c)
+	return refund, nil
+}
+
+// Settlements reports every charge made to this card and how much of each
+// has been refunded.
+func (c *CreditCard) Settlements() []paymentstrategy.Settlement {
+	return c.refunds.Settlements()
+}
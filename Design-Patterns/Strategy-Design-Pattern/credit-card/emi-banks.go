@@ -0,0 +1,63 @@
+package creditcard
+
+import (
+	"strategy-design/money"
+	"strings"
+)
+
+// EMIBank describes one issuer's installment offers. Cards are matched to
+// a bank by BIN prefix. Rates maps a tenure in months to the annual
+// interest rate in basis points; a rate of zero is a no-cost EMI, where
+// the customer pays only the purchase price.
+type EMIBank struct {
+	Name      string
+	BINs      []string
+	MinAmount money.Money
+	Rates     map[int]int
+	// ForeclosureBPS is charged on the outstanding principal when an EMI
+	// is closed early.
+	ForeclosureBPS int
+}
+
+func (b EMIBank) matches(number string) bool {
+	for _, bin := range b.BINs {
+		if strings.HasPrefix(number, bin) {
+			return true
+		}
+	}
+	return false
+}
+
+// EMIBanks lists the issuers offering installments, checked in order.
+var EMIBanks = []EMIBank{
+	{
+		Name:           "Axis Bank",
+		BINs:           []string{"555555", "411111"},
+		MinAmount:      money.MustParse("50.00", money.USD),
+		Rates:          map[int]int{3: 0, 6: 1300, 9: 1400, 12: 1500},
+		ForeclosureBPS: 300,
+	},
+	{
+		Name:           "HDFC Bank",
+		BINs:           []string{"4000", "5105"},
+		MinAmount:      money.MustParse("3000.00", money.INR),
+		Rates:          map[int]int{3: 0, 6: 0, 12: 1400, 24: 1500},
+		ForeclosureBPS: 300,
+	},
+	{
+		Name:           "SBI Card",
+		BINs:           []string{"652150", "508500"},
+		MinAmount:      money.MustParse("2500.00", money.INR),
+		Rates:          map[int]int{6: 1450, 12: 1500, 18: 1550},
+		ForeclosureBPS: 0,
+	},
+}
+
+func emiBankFor(number string) (EMIBank, bool) {
+	for _, b := range EMIBanks {
+		if b.matches(number) {
+			return b, true
+		}
+	}
+	return EMIBank{}, false
+}
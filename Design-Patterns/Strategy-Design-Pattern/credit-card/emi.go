@@ -0,0 +1,272 @@
+package creditcard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+	"time"
+)
+
+var (
+	ErrEMIUnavailable = errors.New("card is not eligible for this EMI")
+	ErrEMIClosed      = errors.New("EMI is already fully paid or closed")
+)
+
+// EMIOption is one tenure a card can take an amount in, priced out.
+type EMIOption struct {
+	Bank          string
+	Tenure        int
+	AnnualRateBPS int
+	Installment   money.Money
+	TotalInterest money.Money
+}
+
+func (o EMIOption) NoCost() bool {
+	return o.AnnualRateBPS == 0
+}
+
+func (o EMIOption) String() string {
+	rate := "no-cost"
+	if !o.NoCost() {
+		rate = fmt.Sprintf("%d.%02d%% p.a.", o.AnnualRateBPS/100, o.AnnualRateBPS%100)
+	}
+	return fmt.Sprintf("%s %d x %s (%s, interest %s)", o.Bank, o.Tenure, o.Installment, rate, o.TotalInterest)
+}
+
+// EMIOptions lists the tenures the card's issuer offers for amount,
+// shortest first. The issuer's minimum is only compared in its own
+// currency, so purchases in any other currency are not eligible.
+func (c *CreditCard) EMIOptions(amount money.Money) ([]EMIOption, error) {
+	bank, ok := emiBankFor(c.cardNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: no EMI offers for %s", ErrEMIUnavailable, c.Masked())
+	}
+	if !amount.SameCurrency(bank.MinAmount) {
+		return nil, fmt.Errorf("%w: %s offers EMI in %s only", ErrEMIUnavailable, bank.Name, bank.MinAmount.Currency())
+	}
+	if cmp, _ := amount.Cmp(bank.MinAmount); cmp < 0 {
+		return nil, fmt.Errorf("%w: %s needs at least %s", ErrEMIUnavailable, bank.Name, bank.MinAmount)
+	}
+	tenures := make([]int, 0, len(bank.Rates))
+	for tenure := range bank.Rates {
+		tenures = append(tenures, tenure)
+	}
+	sort.Ints(tenures)
+	options := make([]EMIOption, 0, len(tenures))
+	for _, tenure := range tenures {
+		rows := amortize(amount, tenure, bank.Rates[tenure], now())
+		interest := money.Zero(amount.Currency())
+		for _, row := range rows {
+			interest, _ = interest.Add(row.Interest)
+		}
+		options = append(options, EMIOption{
+			Bank:          bank.Name,
+			Tenure:        tenure,
+			AnnualRateBPS: bank.Rates[tenure],
+			Installment:   rows[0].Amount,
+			TotalInterest: interest,
+		})
+	}
+	return options, nil
+}
+
+type InstallmentStatus string
+
+const (
+	InstallmentDue  InstallmentStatus = "due"
+	InstallmentPaid InstallmentStatus = "paid"
+)
+
+// Installment is one row of an amortization schedule. Balance is the
+// principal still owed after this installment.
+type Installment struct {
+	Number    int
+	Due       time.Time
+	Amount    money.Money
+	Principal money.Money
+	Interest  money.Money
+	// ForeclosureCharge is set only on the row that pays the EMI off early.
+	ForeclosureCharge money.Money
+	Balance           money.Money
+	Status            InstallmentStatus
+	Receipt           *paymentstrategy.Receipt
+}
+
+// EMI is a purchase being paid off in monthly installments on one card.
+type EMI struct {
+	mu       sync.Mutex
+	card     *CreditCard
+	bank     EMIBank
+	option   EMIOption
+	schedule []Installment
+	closure  *paymentstrategy.Receipt
+}
+
+// StartEMI converts a purchase into tenure monthly installments and charges
+// the first one now. Nothing is charged if the card is not eligible.
+func (c *CreditCard) StartEMI(ctx context.Context, amount money.Money, tenure int) (*EMI, *paymentstrategy.Receipt, error) {
+	options, err := c.EMIOptions(amount)
+	if err != nil {
+		return nil, nil, err
+	}
+	bank, _ := emiBankFor(c.cardNumber)
+	for _, option := range options {
+		if option.Tenure != tenure {
+			continue
+		}
+		emi := &EMI{
+			card:     c,
+			bank:     bank,
+			option:   option,
+			schedule: amortize(amount, tenure, option.AnnualRateBPS, now()),
+		}
+		receipt, err := emi.PayNext(ctx)
+		if err != nil {
+			return nil, receipt, err
+		}
+		return emi, receipt, nil
+	}
+	return nil, nil, fmt.Errorf("%w: %s does not offer %d months", ErrEMIUnavailable, bank.Name, tenure)
+}
+
+func (e *EMI) Option() EMIOption {
+	return e.option
+}
+
+// Schedule returns the amortization schedule with each row's status.
+func (e *EMI) Schedule() []Installment {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Installment(nil), e.schedule...)
+}
+
+// Outstanding is the principal not yet repaid.
+func (e *EMI) Outstanding() money.Money {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.outstanding()
+}
+
+// PayNext charges the earliest unpaid installment.
+func (e *EMI) PayNext(ctx context.Context) (*paymentstrategy.Receipt, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.next()
+	if i < 0 {
+		return nil, ErrEMIClosed
+	}
+	row := &e.schedule[i]
+	receipt, err := e.card.Pay(ctx, row.Amount)
+	if err != nil {
+		return receipt, err
+	}
+	row.Status = InstallmentPaid
+	row.Receipt = receipt
+	return receipt, nil
+}
+
+// Foreclose pays off the EMI early: the outstanding principal plus the
+// bank's foreclosure charge, with no further interest. The remaining rows
+// are replaced by the single row that closes the EMI.
+func (e *EMI) Foreclose(ctx context.Context) (*paymentstrategy.Receipt, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	i := e.next()
+	if i < 0 {
+		return nil, ErrEMIClosed
+	}
+	principal := e.outstanding()
+	charge := principal.Scale(int64(e.bank.ForeclosureBPS), 10000)
+	total, _ := principal.Add(charge)
+	receipt, err := e.card.Pay(ctx, total)
+	if err != nil {
+		return receipt, err
+	}
+	zero := money.Zero(principal.Currency())
+	e.schedule = append(e.schedule[:i], Installment{
+		Number:            e.schedule[i].Number,
+		Due:               now(),
+		Amount:            total,
+		Principal:         principal,
+		Interest:          zero,
+		ForeclosureCharge: charge,
+		Balance:           zero,
+		Status:            InstallmentPaid,
+		Receipt:           receipt,
+	})
+	e.closure = receipt
+	return receipt, nil
+}
+
+func (e *EMI) next() int {
+	if e.closure != nil {
+		return -1
+	}
+	for i, row := range e.schedule {
+		if row.Status == InstallmentDue {
+			return i
+		}
+	}
+	return -1
+}
+
+func (e *EMI) outstanding() money.Money {
+	owed := e.schedule[0].Principal
+	owed, _ = owed.Add(e.schedule[0].Balance)
+	for _, row := range e.schedule {
+		if row.Status == InstallmentPaid {
+			owed, _ = owed.Sub(row.Principal)
+		}
+	}
+	return owed
+}
+
+// amortize builds a reducing-balance schedule with the installment fixed
+// by the usual EMI formula P·r·(1+r)^n / ((1+r)^n − 1) and rounded to the
+// minor unit; the last row absorbs the rounding. At a zero rate the
+// principal is simply split evenly.
+func amortize(principal money.Money, tenure, annualBPS int, start time.Time) []Installment {
+	zero := money.Zero(principal.Currency())
+	rows := make([]Installment, tenure)
+	if annualBPS == 0 {
+		ratios := make([]int64, tenure)
+		for i := range ratios {
+			ratios[i] = 1
+		}
+		balance := principal
+		for i, share := range principal.Allocate(ratios...) {
+			balance, _ = balance.Sub(share)
+			rows[i] = Installment{Number: i + 1, Due: start.AddDate(0, i, 0), Amount: share, Principal: share, Interest: zero, Balance: balance, Status: InstallmentDue}
+		}
+		return rows
+	}
+
+	monthly := big.NewRat(int64(annualBPS), 12*10000)
+	growth := new(big.Rat).Add(big.NewRat(1, 1), monthly)
+	compound := big.NewRat(1, 1)
+	for i := 0; i < tenure; i++ {
+		compound.Mul(compound, growth)
+	}
+	factor := new(big.Rat).Mul(monthly, compound)
+	factor.Quo(factor, new(big.Rat).Sub(compound, big.NewRat(1, 1)))
+	installment := principal.ScaleRat(factor)
+
+	balance := principal
+	for i := range rows {
+		interest := balance.ScaleRat(monthly)
+		amount := installment
+		paid, _ := amount.Sub(interest)
+		if i == tenure-1 {
+			paid = balance
+			amount, _ = paid.Add(interest)
+		}
+		balance, _ = balance.Sub(paid)
+		rows[i] = Installment{Number: i + 1, Due: start.AddDate(0, i, 0), Amount: amount, Principal: paid, Interest: interest, Balance: balance, Status: InstallmentDue}
+	}
+	return rows
+}
@@ -0,0 +1,21 @@
+package creditcard
+
+import (
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// DefaultFees is what card charges cost unless SetFees says otherwise.
+var DefaultFees = paymentstrategy.FeeModel{
+	{Currency: money.USD, PercentBPS: 290, Fixed: money.MustParse("0.30", money.USD), MaxAmount: money.MustParse("25000.00", money.USD)},
+	{Currency: money.EUR, PercentBPS: 250, Fixed: money.MustParse("0.25", money.EUR)},
+	{Currency: money.INR, PercentBPS: 200, MaxAmount: money.MustParse("1000000.00", money.INR)},
+}
+
+func (c *CreditCard) Fees() paymentstrategy.FeeModel {
+	return c.fees
+}
+
+func (c *CreditCard) SetFees(fees paymentstrategy.FeeModel) {
+	c.fees = fees
+}
@@ -0,0 +1,19 @@
+package creditcard
+
+import paymentstrategy "strategy-design/payment-strategy"
+
+// Fingerprint returns a stable token for a card number that reveals none
+// of its digits. Spaces and dashes are ignored, so any formatting of the
+// same number gives the same token. See paymentstrategy.SetFingerprintKey
+// for how the token is keyed.
+func Fingerprint(number string) (string, error) {
+	digits, err := normalizeNumber(number)
+	if err != nil {
+		return "", err
+	}
+	return fingerprint(digits), nil
+}
+
+func fingerprint(digits string) string {
+	return paymentstrategy.Fingerprint("card", digits)
+}
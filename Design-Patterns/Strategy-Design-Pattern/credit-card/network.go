@@ -0,0 +1,73 @@
+package creditcard
+
+import "strconv"
+
+type Network string
+
+const (
+	Visa       Network = "Visa"
+	MasterCard Network = "MasterCard"
+	Amex       Network = "Amex"
+	RuPay      Network = "RuPay"
+	Unknown    Network = "Unknown"
+)
+
+type binRange struct {
+	low, high int
+	digits    int
+	network   Network
+	lengths   []int
+}
+
+// binRanges is checked in order; ranges are expressed on the first digits
+// digits of the card number.
+var binRanges = []binRange{
+	{34, 34, 2, Amex, []int{15}},
+	{37, 37, 2, Amex, []int{15}},
+	{4, 4, 1, Visa, []int{13, 16, 19}},
+	{51, 55, 2, MasterCard, []int{16}},
+	{2221, 2720, 4, MasterCard, []int{16}},
+	{508500, 508999, 6, RuPay, []int{16}},
+	{606985, 607984, 6, RuPay, []int{16}},
+	{608001, 608500, 6, RuPay, []int{16}},
+	{652150, 653149, 6, RuPay, []int{16}},
+	{353, 353, 3, RuPay, []int{16}},
+	{356, 356, 3, RuPay, []int{16}},
+	{81, 82, 2, RuPay, []int{16}},
+}
+
+// DetectNetwork identifies the card network from the BIN and length of a
+// digits-only card number.
+func DetectNetwork(number string) Network {
+	for _, r := range binRanges {
+		if len(number) < r.digits {
+			continue
+		}
+		prefix, err := strconv.Atoi(number[:r.digits])
+		if err != nil || prefix < r.low || prefix > r.high {
+			continue
+		}
+		for _, l := range r.lengths {
+			if len(number) == l {
+				return r.network
+			}
+		}
+	}
+	return Unknown
+}
+
+// country reports where a network's cards are issued when that is implied
+// by the network itself.
+func (n Network) country() string {
+	if n == RuPay {
+		return "IN"
+	}
+	return ""
+}
+
+func (n Network) cvvLength() int {
+	if n == Amex {
+		return 4
+	}
+	return 3
+}
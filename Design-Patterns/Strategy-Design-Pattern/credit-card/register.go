@@ -0,0 +1,38 @@
+package creditcard
+
+import (
+	"fmt"
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// Spec: credit-card:name=...,number=...,expiry=MM/YYYY,cvv=...[,settle=INR,rates=rates.json]
+func init() {
+	paymentstrategy.Register(Method, func(cfg paymentstrategy.Config) (paymentstrategy.PaymentStrategy, error) {
+		number, err := cfg.Require("number")
+		if err != nil {
+			return nil, err
+		}
+		expiry, err := cfg.Require("expiry")
+		if err != nil {
+			return nil, err
+		}
+		var month, year int
+		if _, err := fmt.Sscanf(expiry, "%d/%d", &month, &year); err != nil {
+			return nil, fmt.Errorf("%w: expiry %q must be MM/YYYY", paymentstrategy.ErrInvalidConfig, expiry)
+		}
+		card, err := NewCreditCard(cfg.Get("name"), number, month, year, cfg.Get("cvv"))
+		if err != nil {
+			return nil, err
+		}
+		if settle := cfg.Get("settle"); settle != "" {
+			rates, err := exchangerate.NewFileProvider(cfg.Get("rates"))
+			if err != nil {
+				return nil, err
+			}
+			card.SetSettlementCurrency(money.Currency(settle), rates)
+		}
+		return card, nil
+	})
+}
@@ -0,0 +1,84 @@
+package creditcard
+
+import (
+	"errors"
+	"fmt"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidNumber      = errors.New("card number failed validation")
+	ErrUnsupportedNetwork = errors.New("card network not supported")
+	ErrExpired            = errors.New("card has expired")
+	ErrInvalidExpiry      = errors.New("invalid expiry date")
+	ErrInvalidCVV         = errors.New("invalid CVV")
+)
+
+var now = time.Now
+
+func normalizeNumber(number string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == ' ' || r == '-' {
+			return -1
+		}
+		return r
+	}, number)
+	if len(digits) < 12 || len(digits) > 19 || !isDigits(digits) {
+		return "", invalid(ErrInvalidNumber)
+	}
+	if !luhnValid(digits) {
+		return "", invalid(ErrInvalidNumber)
+	}
+	return digits, nil
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// validateExpiry accepts a card until the last moment of its expiry month.
+func validateExpiry(month, year int) error {
+	if month < 1 || month > 12 || year < 2000 {
+		return invalid(ErrInvalidExpiry)
+	}
+	endOfMonth := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	if !now().Before(endOfMonth) {
+		return invalid(ErrExpired)
+	}
+	return nil
+}
+
+func validateCVV(cvv string, network Network) error {
+	if len(cvv) != network.cvvLength() || !isDigits(cvv) {
+		return invalid(ErrInvalidCVV)
+	}
+	return nil
+}
+
+func invalid(err error) error {
+	return fmt.Errorf("%w: %w", paymentstrategy.ErrInvalidInstrument, err)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
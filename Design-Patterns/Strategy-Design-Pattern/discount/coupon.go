@@ -0,0 +1,114 @@
+package discount
+
+import (
+	"fmt"
+	"strategy-design/money"
+	"time"
+)
+
+type Kind string
+
+const (
+	Percentage   Kind = "percentage"
+	Flat         Kind = "flat"
+	BuyXGetY     Kind = "buy-x-get-y"
+	MinimumSpend Kind = "minimum-spend"
+)
+
+type Coupon struct {
+	Code string
+	Kind Kind
+
+	// Percent is used by Percentage coupons, optionally capped by MaxDiscount.
+	Percent     float64
+	MaxDiscount money.Money
+	// Amount is taken off by Flat and MinimumSpend coupons.
+	Amount money.Money
+	// SKU, BuyQuantity and FreeQuantity describe a BuyXGetY offer: for every
+	// BuyQuantity units of SKU bought, FreeQuantity more are free.
+	SKU          string
+	BuyQuantity  int
+	FreeQuantity int
+	// MinSpend is the subtotal the cart must reach before the coupon
+	// applies. It is required for MinimumSpend and optional for the rest.
+	MinSpend money.Money
+
+	// Exclusive coupons cannot be combined with any other coupon.
+	Exclusive bool
+	// ExpiresAt is the first instant the coupon is no longer valid; the zero
+	// value means it never expires.
+	ExpiresAt time.Time
+}
+
+func NewPercentage(code string, percent float64) Coupon {
+	return Coupon{Code: code, Kind: Percentage, Percent: percent}
+}
+
+func NewFlat(code string, amount money.Money) Coupon {
+	return Coupon{Code: code, Kind: Flat, Amount: amount}
+}
+
+func NewBuyXGetY(code, sku string, buy, free int) Coupon {
+	return Coupon{Code: code, Kind: BuyXGetY, SKU: sku, BuyQuantity: buy, FreeQuantity: free}
+}
+
+func NewMinimumSpend(code string, minSpend, amount money.Money) Coupon {
+	return Coupon{Code: code, Kind: MinimumSpend, MinSpend: minSpend, Amount: amount}
+}
+
+func (c Coupon) Validate() error {
+	if c.Code == "" {
+		return fmt.Errorf("%w: coupon code is empty", ErrInvalidCoupon)
+	}
+	switch c.Kind {
+	case Percentage:
+		if c.Percent <= 0 || c.Percent > 100 {
+			return fmt.Errorf("%w: %s percent must be in (0, 100]", ErrInvalidCoupon, c.Code)
+		}
+	case Flat:
+		if !c.Amount.IsPositive() {
+			return fmt.Errorf("%w: %s amount must be positive", ErrInvalidCoupon, c.Code)
+		}
+	case BuyXGetY:
+		if c.SKU == "" || c.BuyQuantity <= 0 || c.FreeQuantity <= 0 {
+			return fmt.Errorf("%w: %s needs a SKU and positive buy/free quantities", ErrInvalidCoupon, c.Code)
+		}
+	case MinimumSpend:
+		if !c.MinSpend.IsPositive() || !c.Amount.IsPositive() {
+			return fmt.Errorf("%w: %s needs a positive minimum spend and amount", ErrInvalidCoupon, c.Code)
+		}
+	default:
+		return fmt.Errorf("%w: %s has unknown kind %q", ErrInvalidCoupon, c.Code, c.Kind)
+	}
+	return nil
+}
+
+func (c Coupon) Expired(at time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !at.Before(c.ExpiresAt)
+}
+
+func (c Coupon) Description() string {
+	switch c.Kind {
+	case Percentage:
+		return fmt.Sprintf("%g%% off", c.Percent)
+	case Flat:
+		return fmt.Sprintf("%s off", c.Amount)
+	case BuyXGetY:
+		return fmt.Sprintf("buy %d get %d free on %s", c.BuyQuantity, c.FreeQuantity, c.SKU)
+	case MinimumSpend:
+		return fmt.Sprintf("%s off orders over %s", c.Amount, c.MinSpend)
+	}
+	return string(c.Kind)
+}
+
+// order fixes the sequence coupons are applied in: item offers first, then
+// percentages on what is left, then fixed amounts.
+func (c Coupon) order() int {
+	switch c.Kind {
+	case BuyXGetY:
+		return 0
+	case Percentage:
+		return 1
+	}
+	return 2
+}
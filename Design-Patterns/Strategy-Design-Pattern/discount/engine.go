@@ -0,0 +1,195 @@
+package discount
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strategy-design/money"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidCoupon       = errors.New("invalid coupon")
+	ErrUnknownCoupon       = errors.New("unknown coupon")
+	ErrCouponExpired       = errors.New("coupon has expired")
+	ErrCouponNotCombinable = errors.New("coupon cannot be combined with the other coupons")
+	ErrCouponNotApplicable = errors.New("coupon does not apply to this cart")
+)
+
+type Item struct {
+	SKU       string
+	UnitPrice money.Money
+	Quantity  int
+}
+
+// Applied is one discount line. SKU is set when the discount belongs to a
+// single line item rather than the whole order.
+type Applied struct {
+	Code        string
+	Description string
+	SKU         string
+	Amount      money.Money
+}
+
+type Rejection struct {
+	Code string
+	Err  error
+}
+
+type Result struct {
+	Subtotal money.Money
+	Discount money.Money
+	Total    money.Money
+	Applied  []Applied
+	Rejected []Rejection
+}
+
+type Engine struct {
+	mu      sync.RWMutex
+	coupons map[string]Coupon
+	now     func() time.Time
+}
+
+func NewEngine() *Engine {
+	return &Engine{
+		coupons: make(map[string]Coupon),
+		now:     time.Now,
+	}
+}
+
+func (e *Engine) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
+}
+
+func (e *Engine) Add(c Coupon) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.coupons[c.Code] = c
+	return nil
+}
+
+// Check reports whether code may be added to a cart already holding codes.
+// Spend and SKU conditions are left to Apply, since the cart can change.
+func (e *Engine) Check(code string, codes []string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	c, err := e.lookup(code)
+	if err != nil {
+		return err
+	}
+	for _, other := range codes {
+		if other == code {
+			continue
+		}
+		if o, ok := e.coupons[other]; ok && (c.Exclusive || o.Exclusive) {
+			return fmt.Errorf("%w: %s and %s", ErrCouponNotCombinable, code, other)
+		}
+	}
+	return nil
+}
+
+// Apply prices items with the given coupon codes. Unknown, expired or
+// clashing codes fail the whole call; coupons whose conditions the cart
+// does not meet are listed in Rejected and contribute nothing.
+func (e *Engine) Apply(codes []string, items []Item, currency money.Currency) (*Result, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	subtotal := money.Zero(currency)
+	for _, item := range items {
+		line := item.UnitPrice.Multiply(int64(item.Quantity))
+		var err error
+		if subtotal, err = subtotal.Add(line); err != nil {
+			return nil, err
+		}
+	}
+
+	coupons := make([]Coupon, 0, len(codes))
+	for _, code := range codes {
+		c, err := e.lookup(code)
+		if err != nil {
+			return nil, err
+		}
+		if c.Exclusive && len(codes) > 1 {
+			return nil, fmt.Errorf("%w: %s is exclusive", ErrCouponNotCombinable, code)
+		}
+		coupons = append(coupons, c)
+	}
+	sort.SliceStable(coupons, func(i, j int) bool { return coupons[i].order() < coupons[j].order() })
+
+	result := &Result{Subtotal: subtotal}
+	remaining := subtotal
+	for _, c := range coupons {
+		amount, sku, err := c.discount(items, subtotal, remaining)
+		if err != nil {
+			result.Rejected = append(result.Rejected, Rejection{Code: c.Code, Err: err})
+			continue
+		}
+		if cmp, _ := amount.Cmp(remaining); cmp > 0 {
+			amount = remaining
+		}
+		if amount.IsZero() {
+			continue
+		}
+		remaining, _ = remaining.Sub(amount)
+		result.Applied = append(result.Applied, Applied{Code: c.Code, Description: c.Description(), SKU: sku, Amount: amount})
+	}
+	result.Total = remaining
+	result.Discount, _ = subtotal.Sub(remaining)
+	return result, nil
+}
+
+func (e *Engine) lookup(code string) (Coupon, error) {
+	c, ok := e.coupons[code]
+	if !ok {
+		return Coupon{}, fmt.Errorf("%w: %s", ErrUnknownCoupon, code)
+	}
+	if c.Expired(e.now()) {
+		return Coupon{}, fmt.Errorf("%w: %s", ErrCouponExpired, code)
+	}
+	return c, nil
+}
+
+func (c Coupon) discount(items []Item, subtotal, remaining money.Money) (money.Money, string, error) {
+	if !c.MinSpend.IsZero() {
+		if cmp, err := subtotal.Cmp(c.MinSpend); err != nil {
+			return money.Money{}, "", err
+		} else if cmp < 0 {
+			return money.Money{}, "", fmt.Errorf("%w: %s needs a subtotal of at least %s", ErrCouponNotApplicable, c.Code, c.MinSpend)
+		}
+	}
+	switch c.Kind {
+	case Percentage:
+		amount := remaining.ScaleFloat(c.Percent / 100)
+		if !c.MaxDiscount.IsZero() {
+			if cmp, _ := amount.Cmp(c.MaxDiscount); cmp > 0 {
+				amount = c.MaxDiscount
+			}
+		}
+		return amount, "", nil
+	case Flat, MinimumSpend:
+		if !c.Amount.SameCurrency(subtotal) {
+			return money.Money{}, "", fmt.Errorf("%w: %s is in %s", money.ErrCurrencyMismatch, c.Code, c.Amount.Currency())
+		}
+		return c.Amount, "", nil
+	case BuyXGetY:
+		for _, item := range items {
+			if item.SKU != c.SKU {
+				continue
+			}
+			free := item.Quantity / (c.BuyQuantity + c.FreeQuantity) * c.FreeQuantity
+			if free == 0 {
+				break
+			}
+			return item.UnitPrice.Multiply(int64(free)), item.SKU, nil
+		}
+		return money.Money{}, "", fmt.Errorf("%w: %s needs %d+%d of %s", ErrCouponNotApplicable, c.Code, c.BuyQuantity, c.FreeQuantity, c.SKU)
+	}
+	return money.Money{}, "", fmt.Errorf("%w: %s", ErrInvalidCoupon, c.Code)
+}
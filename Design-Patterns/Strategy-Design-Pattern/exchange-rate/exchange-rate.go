@@ -0,0 +1,34 @@
+package exchangerate
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+)
+
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// Provider returns how many units of to one unit of from buys.
+type Provider interface {
+	Rate(from, to money.Currency) (float64, error)
+}
+
+// Convert settles amount in the target currency using p and returns the
+// converted amount together with the rate that was applied.
+func Convert(p Provider, amount money.Money, to money.Currency) (money.Money, float64, error) {
+	if amount.Currency() == to {
+		return amount, 1, nil
+	}
+	if p == nil {
+		return money.Money{}, 0, fmt.Errorf("%w: %s/%s (no provider)", ErrRateNotFound, amount.Currency(), to)
+	}
+	rate, err := p.Rate(amount.Currency(), to)
+	if err != nil {
+		return money.Money{}, 0, err
+	}
+	settled, err := amount.Convert(to, rate)
+	if err != nil {
+		return money.Money{}, 0, err
+	}
+	return settled, rate, nil
+}
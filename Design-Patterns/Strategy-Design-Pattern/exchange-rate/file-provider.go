@@ -0,0 +1,57 @@
+package exchangerate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strategy-design/money"
+	"strings"
+)
+
+// FileProvider loads rates from a JSON file of the form
+//
+//	{"rates": {"USD/INR": 83.2, "USD/BTC": 0.0000152}}
+//
+// and can be reloaded when the file changes.
+type FileProvider struct {
+	*StaticProvider
+	path string
+}
+
+func NewFileProvider(path string) (*FileProvider, error) {
+	f := &FileProvider{
+		StaticProvider: NewStaticProvider(),
+		path:           path,
+	}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileProvider) Reload() error {
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return fmt.Errorf("read exchange rates: %w", err)
+	}
+	var doc struct {
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse exchange rates %s: %w", f.path, err)
+	}
+	rates := make(map[pair]float64, len(doc.Rates))
+	for key, rate := range doc.Rates {
+		from, to, ok := strings.Cut(key, "/")
+		p := pair{money.Currency(from), money.Currency(to)}
+		if !ok || !p.from.Valid() || !p.to.Valid() {
+			return fmt.Errorf("parse exchange rates %s: bad pair %q", f.path, key)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("parse exchange rates %s: rate for %q must be positive", f.path, key)
+		}
+		rates[p] = rate
+	}
+	f.replace(rates)
+	return nil
+}
@@ -0,0 +1,59 @@
+package exchangerate
+
+import (
+	"fmt"
+	"strategy-design/money"
+	"sync"
+)
+
+type pair struct {
+	from money.Currency
+	to   money.Currency
+}
+
+// StaticProvider serves rates from an in-memory table. A pair that is only
+// known in the opposite direction is answered with the inverse rate.
+type StaticProvider struct {
+	mu    sync.RWMutex
+	rates map[pair]float64
+}
+
+func NewStaticProvider() *StaticProvider {
+	return &StaticProvider{
+		rates: make(map[pair]float64),
+	}
+}
+
+func (s *StaticProvider) Set(from, to money.Currency, rate float64) error {
+	if !from.Valid() || !to.Valid() {
+		return fmt.Errorf("%w: %s/%s", money.ErrUnknownCurrency, from, to)
+	}
+	if rate <= 0 {
+		return fmt.Errorf("exchange rate %s/%s must be positive, got %v", from, to, rate)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates[pair{from, to}] = rate
+	return nil
+}
+
+func (s *StaticProvider) Rate(from, to money.Currency) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if rate, ok := s.rates[pair{from, to}]; ok {
+		return rate, nil
+	}
+	if rate, ok := s.rates[pair{to, from}]; ok {
+		return 1 / rate, nil
+	}
+	return 0, fmt.Errorf("%w: %s/%s", ErrRateNotFound, from, to)
+}
+
+func (s *StaticProvider) replace(rates map[pair]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rates = rates
+}
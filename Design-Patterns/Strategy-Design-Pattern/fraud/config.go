@@ -0,0 +1,124 @@
+package fraud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strategy-design/money"
+	"time"
+)
+
+// Config is the on-disk form of a rule set:
+//
+//	{
+//	  "velocity": [{"window": "10m", "max_count": 3, "decision": "review"}],
+//	  "thresholds": [{"currency": "USD", "review_over": "1000.00", "deny_over": "5000.00"}],
+//	  "blocklist": {"emails": [], "email_domains": [], "cards": [], "wallets": []},
+//	  "mismatch": {"issuer_country": "review", "email_country": "review"}
+//	}
+type Config struct {
+	Velocity []struct {
+		Window    string   `json:"window"`
+		MaxCount  int      `json:"max_count"`
+		Currency  string   `json:"currency"`
+		MaxAmount string   `json:"max_amount"`
+		Decision  Decision `json:"decision"`
+	} `json:"velocity"`
+	Thresholds []struct {
+		Currency   string `json:"currency"`
+		ReviewOver string `json:"review_over"`
+		DenyOver   string `json:"deny_over"`
+	} `json:"thresholds"`
+	Blocklist struct {
+		Emails       []string `json:"emails"`
+		EmailDomains []string `json:"email_domains"`
+		Cards        []string `json:"cards"`
+		Wallets      []string `json:"wallets"`
+	} `json:"blocklist"`
+	Mismatch struct {
+		IssuerCountry Decision `json:"issuer_country"`
+		EmailCountry  Decision `json:"email_country"`
+	} `json:"mismatch"`
+}
+
+func LoadFile(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read fraud rules: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse fraud rules %s: %w", path, err)
+	}
+	rules, err := cfg.Rules()
+	if err != nil {
+		return nil, fmt.Errorf("parse fraud rules %s: %w", path, err)
+	}
+	return NewEngine(rules...), nil
+}
+
+// Rules builds the rules described by c.
+func (c Config) Rules() ([]Rule, error) {
+	var rules []Rule
+
+	block := NewBlocklistRule()
+	for _, email := range c.Blocklist.Emails {
+		block.BlockEmail(email)
+	}
+	for _, domain := range c.Blocklist.EmailDomains {
+		block.BlockDomain(domain)
+	}
+	for _, card := range c.Blocklist.Cards {
+		block.BlockInstrument(card)
+	}
+	for _, wallet := range c.Blocklist.Wallets {
+		block.BlockInstrument(wallet)
+	}
+	rules = append(rules, block)
+
+	for i, v := range c.Velocity {
+		window, err := time.ParseDuration(v.Window)
+		if err != nil || window <= 0 {
+			return nil, fmt.Errorf("velocity rule %d: bad window %q", i+1, v.Window)
+		}
+		if !v.Decision.valid() {
+			return nil, fmt.Errorf("velocity rule %d: bad decision %q", i+1, v.Decision)
+		}
+		var limit money.Money
+		if v.MaxAmount != "" {
+			if limit, err = money.Parse(v.MaxAmount, money.Currency(v.Currency)); err != nil {
+				return nil, fmt.Errorf("velocity rule %d: %w", i+1, err)
+			}
+		}
+		if v.MaxCount <= 0 && !limit.IsPositive() {
+			return nil, fmt.Errorf("velocity rule %d: needs max_count or max_amount", i+1)
+		}
+		rules = append(rules, NewVelocityRule(window, v.MaxCount, limit, v.Decision))
+	}
+
+	for i, t := range c.Thresholds {
+		rule := &ThresholdRule{}
+		var err error
+		if t.ReviewOver != "" {
+			if rule.ReviewOver, err = money.Parse(t.ReviewOver, money.Currency(t.Currency)); err != nil {
+				return nil, fmt.Errorf("threshold %d: %w", i+1, err)
+			}
+		}
+		if t.DenyOver != "" {
+			if rule.DenyOver, err = money.Parse(t.DenyOver, money.Currency(t.Currency)); err != nil {
+				return nil, fmt.Errorf("threshold %d: %w", i+1, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	for _, d := range []Decision{c.Mismatch.IssuerCountry, c.Mismatch.EmailCountry} {
+		if d != "" && !d.valid() {
+			return nil, fmt.Errorf("mismatch: bad decision %q", d)
+		}
+	}
+	if c.Mismatch.IssuerCountry != "" || c.Mismatch.EmailCountry != "" {
+		rules = append(rules, &MismatchRule{IssuerCountry: c.Mismatch.IssuerCountry, EmailCountry: c.Mismatch.EmailCountry})
+	}
+	return rules, nil
+}
@@ -0,0 +1,153 @@
+package fraud
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrDenied = errors.New("payment denied by fraud screening")
+	ErrReview = errors.New("payment held for manual fraud review")
+)
+
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Review Decision = "review"
+	Deny   Decision = "deny"
+)
+
+func (d Decision) severity() int {
+	switch d {
+	case Deny:
+		return 2
+	case Review:
+		return 1
+	}
+	return 0
+}
+
+func (d Decision) valid() bool {
+	return d == Allow || d == Review || d == Deny
+}
+
+// Transaction is what the engine knows about a charge before it is made.
+type Transaction struct {
+	Instrument paymentstrategy.Instrument
+	Amount     money.Money
+	// ShipTo is the ISO 3166 code of the shipping country, if any.
+	ShipTo string
+	Time   time.Time
+}
+
+// Reason is one rule's verdict on a transaction.
+type Reason struct {
+	Rule     string
+	Decision Decision
+	Detail   string
+}
+
+func (r Reason) String() string {
+	return fmt.Sprintf("%s (%s): %s", r.Rule, r.Decision, r.Detail)
+}
+
+// Result carries the strictest decision any rule reached and every reason
+// that was not an allow.
+type Result struct {
+	Decision Decision
+	Reasons  []Reason
+}
+
+// Err returns nil for an allow and a *ScreeningError otherwise.
+func (r *Result) Err() error {
+	if r.Decision == Allow {
+		return nil
+	}
+	return &ScreeningError{Result: r}
+}
+
+// ScreeningError unwraps to ErrDenied or ErrReview.
+type ScreeningError struct {
+	Result *Result
+}
+
+func (e *ScreeningError) Error() string {
+	reasons := make([]string, 0, len(e.Result.Reasons))
+	for _, r := range e.Result.Reasons {
+		reasons = append(reasons, r.String())
+	}
+	return fmt.Sprintf("%v: %s", e.Unwrap(), strings.Join(reasons, "; "))
+}
+
+func (e *ScreeningError) Unwrap() error {
+	if e.Result.Decision == Deny {
+		return ErrDenied
+	}
+	return ErrReview
+}
+
+// Rule inspects a transaction and returns nil when it has no objection.
+type Rule interface {
+	Name() string
+	Evaluate(tx Transaction) *Reason
+}
+
+// Engine runs every rule against a transaction. It is safe for concurrent
+// use as long as its rules are.
+type Engine struct {
+	mu    sync.RWMutex
+	rules []Rule
+	now   func() time.Time
+}
+
+func NewEngine(rules ...Rule) *Engine {
+	return &Engine{rules: rules, now: time.Now}
+}
+
+func (e *Engine) SetClock(now func() time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.now = now
+}
+
+func (e *Engine) Add(rule Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rules = append(e.rules, rule)
+}
+
+func (e *Engine) Rules() []Rule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]Rule(nil), e.rules...)
+}
+
+// Evaluate screens tx, stamping it with the engine's clock when tx.Time is
+// unset. Every rule runs, even after a deny, so the result lists all the
+// reasons at once and velocity rules count every attempt.
+func (e *Engine) Evaluate(tx Transaction) *Result {
+	e.mu.RLock()
+	rules, now := e.rules, e.now
+	e.mu.RUnlock()
+	if tx.Time.IsZero() {
+		tx.Time = now()
+	}
+	result := &Result{Decision: Allow}
+	for _, rule := range rules {
+		reason := rule.Evaluate(tx)
+		if reason == nil || reason.Decision == Allow {
+			continue
+		}
+		result.Reasons = append(result.Reasons, *reason)
+		if reason.Decision.severity() > result.Decision.severity() {
+			result.Decision = reason.Decision
+		}
+	}
+	return result
+}
@@ -0,0 +1,237 @@
+package fraud
+
+import (
+	"fmt"
+	creditcard "strategy-design/credit-card"
+	giftcard "strategy-design/gift-card"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VelocityRule limits how often, or how much, one instrument may be charged
+// within a sliding window. A zero MaxCount or MaxAmount disables that
+// limit; MaxAmount only sums charges in its own currency.
+type VelocityRule struct {
+	Window    time.Duration
+	MaxCount  int
+	MaxAmount money.Money
+	Decision  Decision
+
+	mu      sync.Mutex
+	history map[string][]velocityEntry
+}
+
+type velocityEntry struct {
+	at     time.Time
+	amount money.Money
+}
+
+func NewVelocityRule(window time.Duration, maxCount int, maxAmount money.Money, decision Decision) *VelocityRule {
+	return &VelocityRule{
+		Window:    window,
+		MaxCount:  maxCount,
+		MaxAmount: maxAmount,
+		Decision:  decision,
+		history:   make(map[string][]velocityEntry),
+	}
+}
+
+func (r *VelocityRule) Name() string {
+	return "velocity/" + r.Window.String()
+}
+
+// Evaluate counts tx against its instrument's history and then records it,
+// so an attempt that trips the limit still counts towards the next one.
+func (r *VelocityRule) Evaluate(tx Transaction) *Reason {
+	if tx.Instrument.ID == "" {
+		return nil
+	}
+	key := tx.Instrument.Method + ":" + tx.Instrument.ID
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cutoff := tx.Time.Add(-r.Window)
+	kept := r.history[key][:0]
+	for _, e := range r.history[key] {
+		if e.at.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	kept = append(kept, velocityEntry{at: tx.Time, amount: tx.Amount})
+	r.history[key] = kept
+
+	if r.MaxCount > 0 && len(kept) > r.MaxCount {
+		return &Reason{Rule: r.Name(), Decision: r.Decision, Detail: fmt.Sprintf("%d charges on %s within %s, limit %d", len(kept), display(tx.Instrument), r.Window, r.MaxCount)}
+	}
+	if r.MaxAmount.IsPositive() {
+		total := money.Zero(r.MaxAmount.Currency())
+		for _, e := range kept {
+			if e.amount.SameCurrency(total) {
+				total, _ = total.Add(e.amount)
+			}
+		}
+		if cmp, _ := total.Cmp(r.MaxAmount); cmp > 0 {
+			return &Reason{Rule: r.Name(), Decision: r.Decision, Detail: fmt.Sprintf("%s charged on %s within %s, limit %s", total, display(tx.Instrument), r.Window, r.MaxAmount)}
+		}
+	}
+	return nil
+}
+
+// ThresholdRule flags single charges above fixed amounts. Either bound may
+// be zero to disable it; charges in other currencies are ignored.
+type ThresholdRule struct {
+	ReviewOver money.Money
+	DenyOver   money.Money
+}
+
+func (r *ThresholdRule) Name() string {
+	return "threshold"
+}
+
+func (r *ThresholdRule) Evaluate(tx Transaction) *Reason {
+	over := func(limit money.Money) bool {
+		if !limit.IsPositive() || !tx.Amount.SameCurrency(limit) {
+			return false
+		}
+		cmp, _ := tx.Amount.Cmp(limit)
+		return cmp > 0
+	}
+	switch {
+	case over(r.DenyOver):
+		return &Reason{Rule: r.Name(), Decision: Deny, Detail: fmt.Sprintf("%s exceeds %s", tx.Amount, r.DenyOver)}
+	case over(r.ReviewOver):
+		return &Reason{Rule: r.Name(), Decision: Review, Detail: fmt.Sprintf("%s exceeds %s", tx.Amount, r.ReviewOver)}
+	}
+	return nil
+}
+
+// BlocklistRule denies known-bad emails, email domains and instrument IDs.
+// Cards are matched on their fingerprint, so full card numbers and gift
+// card codes given to BlockInstrument are fingerprinted first and never
+// kept. Entries are therefore tied to the fingerprint key in force when
+// they are added; see paymentstrategy.SetFingerprintKey.
+type BlocklistRule struct {
+	mu          sync.RWMutex
+	emails      map[string]bool
+	domains     map[string]bool
+	instruments map[string]bool
+}
+
+func NewBlocklistRule() *BlocklistRule {
+	return &BlocklistRule{
+		emails:      make(map[string]bool),
+		domains:     make(map[string]bool),
+		instruments: make(map[string]bool),
+	}
+}
+
+func (r *BlocklistRule) Name() string {
+	return "blocklist"
+}
+
+func (r *BlocklistRule) BlockEmail(email string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emails[strings.ToLower(strings.TrimSpace(email))] = true
+}
+
+func (r *BlocklistRule) BlockDomain(domain string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.domains[strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))] = true
+}
+
+func (r *BlocklistRule) BlockInstrument(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.instruments[CardKey(id)] = true
+}
+
+func (r *BlocklistRule) Evaluate(tx Transaction) *Reason {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.instruments[tx.Instrument.ID] {
+		return &Reason{Rule: r.Name(), Decision: Deny, Detail: fmt.Sprintf("%s %s is blocklisted", tx.Instrument.Method, display(tx.Instrument))}
+	}
+	email := strings.ToLower(tx.Instrument.Email)
+	if email == "" {
+		return nil
+	}
+	if r.emails[email] {
+		return &Reason{Rule: r.Name(), Decision: Deny, Detail: fmt.Sprintf("email %s is blocklisted", email)}
+	}
+	if _, domain, ok := strings.Cut(email, "@"); ok && r.domains[domain] {
+		return &Reason{Rule: r.Name(), Decision: Deny, Detail: fmt.Sprintf("email domain %s is blocklisted", domain)}
+	}
+	return nil
+}
+
+// CardKey turns a card number or gift card code into the fingerprint it
+// is identified by. Anything else is returned unchanged.
+func CardKey(id string) string {
+	id = strings.TrimSpace(id)
+	if key, err := creditcard.Fingerprint(id); err == nil {
+		return key
+	}
+	if key, err := giftcard.Fingerprint(id); err == nil {
+		return key
+	}
+	return id
+}
+
+// display names an instrument in a reason without exposing its ID.
+func display(instrument paymentstrategy.Instrument) string {
+	if instrument.Display != "" {
+		return instrument.Display
+	}
+	return instrument.Method
+}
+
+// MismatchRule flags charges whose instrument looks like it belongs
+// somewhere other than where the order is going: a card issued in one
+// country shipping to another, or an email under another country's
+// domain. Either check is skipped when its decision is empty.
+type MismatchRule struct {
+	IssuerCountry Decision
+	EmailCountry  Decision
+}
+
+func (r *MismatchRule) Name() string {
+	return "mismatch"
+}
+
+func (r *MismatchRule) Evaluate(tx Transaction) *Reason {
+	ship := strings.ToUpper(tx.ShipTo)
+	if ship == "" {
+		return nil
+	}
+	if r.IssuerCountry != "" && tx.Instrument.Country != "" && !strings.EqualFold(tx.Instrument.Country, ship) {
+		return &Reason{Rule: r.Name(), Decision: r.IssuerCountry, Detail: fmt.Sprintf("%s issued in %s, shipping to %s", display(tx.Instrument), tx.Instrument.Country, ship)}
+	}
+	if r.EmailCountry != "" {
+		if country := emailCountry(tx.Instrument.Email); country != "" && country != ship {
+			return &Reason{Rule: r.Name(), Decision: r.EmailCountry, Detail: fmt.Sprintf("email %s suggests %s, shipping to %s", tx.Instrument.Email, country, ship)}
+		}
+	}
+	return nil
+}
+
+// emailCountry guesses a country from a two-letter top-level domain.
+// Generic domains such as .com tell us nothing and yield "".
+func emailCountry(email string) string {
+	dot := strings.LastIndexByte(email, '.')
+	if dot < 0 || !strings.Contains(email, "@") {
+		return ""
+	}
+	tld := strings.ToUpper(email[dot+1:])
+	if len(tld) != 2 {
+		return ""
+	}
+	if tld == "UK" {
+		return "GB"
+	}
+	return tld
+}
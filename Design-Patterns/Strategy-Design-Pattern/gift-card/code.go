@@ -0,0 +1,94 @@
+package giftcard
+
+import (
+	"crypto/rand"
+	"math/big"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+)
+
+// alphabet is Crockford's base32: no I, L, O or U to misread.
+const alphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const codeLength = 16
+
+// NewCode returns a random 16-character code, grouped in fours, whose last
+// character is a Luhn mod 32 check digit over the rest.
+func NewCode() (string, error) {
+	body := make([]byte, codeLength-1)
+	size := big.NewInt(int64(len(alphabet)))
+	for i := range body {
+		n, err := rand.Int(rand.Reader, size)
+		if err != nil {
+			return "", err
+		}
+		body[i] = alphabet[n.Int64()]
+	}
+	return format(string(body) + string(checkDigit(string(body)))), nil
+}
+
+// NormalizeCode strips separators and upper-cases a code as typed by a
+// customer.
+func NormalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(code))
+}
+
+func ValidCode(code string) bool {
+	code = NormalizeCode(code)
+	if len(code) != codeLength {
+		return false
+	}
+	n := len(alphabet)
+	sum := 0
+	factor := 1
+	for i := len(code) - 1; i >= 0; i-- {
+		idx := strings.IndexByte(alphabet, code[i])
+		if idx < 0 {
+			return false
+		}
+		addend := factor * idx
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return sum%n == 0
+}
+
+func checkDigit(body string) byte {
+	n := len(alphabet)
+	sum := 0
+	factor := 2
+	for i := len(body) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(alphabet, body[i])
+		sum += addend/n + addend%n
+		factor = 3 - factor
+	}
+	return alphabet[(n-sum%n)%n]
+}
+
+func format(code string) string {
+	groups := make([]string, 0, len(code)/4)
+	for i := 0; i < len(code); i += 4 {
+		groups = append(groups, code[i:i+4])
+	}
+	return strings.Join(groups, "-")
+}
+
+// Fingerprint returns a stable token for a gift card code that reveals
+// none of it, so rules can match a card without keeping its code. Any
+// formatting of the same code gives the same token.
+func Fingerprint(code string) (string, error) {
+	if !ValidCode(code) {
+		return "", ErrInvalidCode
+	}
+	return paymentstrategy.Fingerprint("gift", NormalizeCode(code)), nil
+}
+
+func mask(code string) string {
+	code = NormalizeCode(code)
+	return "****-" + code[len(code)-4:]
+}
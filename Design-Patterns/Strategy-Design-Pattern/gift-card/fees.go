@@ -0,0 +1,37 @@
+package giftcard
+
+import (
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// DefaultFees makes gift card redemptions free in every currency cards
+// are issued in.
+var DefaultFees = paymentstrategy.FeeModel{
+	{Currency: money.USD},
+	{Currency: money.EUR},
+	{Currency: money.INR},
+}
+
+func (g *GiftCard) Fees() paymentstrategy.FeeModel {
+	return g.fees
+}
+
+func (g *GiftCard) SetFees(fees paymentstrategy.FeeModel) {
+	g.fees = fees
+}
+
+// CanPay checks the card is usable and holds at least amount.
+func (g *GiftCard) CanPay(amount money.Money) error {
+	balance, err := g.store.Balance(g.code)
+	if err != nil {
+		return instrumentError(err)
+	}
+	if cmp, err := balance.Cmp(amount); err != nil {
+		return err
+	} else if cmp < 0 {
+		return fmt.Errorf("%w: %s left on %s", paymentstrategy.ErrInsufficientFunds, balance, mask(g.code))
+	}
+	return nil
+}
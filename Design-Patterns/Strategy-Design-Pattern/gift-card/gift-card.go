@@ -0,0 +1,90 @@
+package giftcard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+const Method = "gift-card"
+
+// DefaultStore backs gift cards built through the strategy registry.
+var DefaultStore = NewStore()
+
+type GiftCard struct {
+	store   *Store
+	code    string
+	refunds *paymentstrategy.RefundBook
+	fees    paymentstrategy.FeeModel
+}
+
+func NewGiftCard(store *Store, code string) (*GiftCard, error) {
+	if !ValidCode(code) {
+		return nil, fmt.Errorf("%w: %w", paymentstrategy.ErrInvalidInstrument, ErrInvalidCode)
+	}
+	return &GiftCard{
+		store:   store,
+		code:    code,
+		refunds: paymentstrategy.NewRefundBook(),
+		fees:    DefaultFees,
+	}, nil
+}
+
+func (g *GiftCard) Balance() (money.Money, error) {
+	return g.store.Balance(g.code)
+}
+
+// Identify keys the card by its fingerprint; the last four characters are
+// too few to tell cards apart.
+func (g *GiftCard) Identify() paymentstrategy.Instrument {
+	id, _ := Fingerprint(g.code)
+	return paymentstrategy.Instrument{Method: Method, ID: id, Display: mask(g.code)}
+}
+
+func (g *GiftCard) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	receipt.Instrument = mask(g.code)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	if _, err := g.store.Redeem(g.code, amount, receipt.TransactionID); err != nil {
+		return receipt.Fail(instrumentError(err))
+	}
+	receipt.Fee = g.fees.Fee(amount)
+	fmt.Printf("Paid %s using Gift Card: %s\n", amount, mask(g.code))
+	g.refunds.Record(receipt)
+	return receipt, nil
+}
+
+func (g *GiftCard) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	refund := paymentstrategy.NewRefundReceipt(original, amount)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return refund.Fail(err)
+	}
+	if err := g.refunds.Reserve(original, amount); err != nil {
+		return refund.Fail(err)
+	}
+	if _, err := g.store.Credit(g.code, amount, refund.TransactionID); err != nil {
+		g.refunds.Release(original, amount)
+		return refund.Fail(instrumentError(err))
+	}
+	fmt.Printf("Refunded %s to Gift Card: %s\n", amount, mask(g.code))
+	return refund, nil
+}
+
+// Settlements reports every charge made to this gift card and how much of each
+// has been refunded.
+func (g *GiftCard) Settlements() []paymentstrategy.Settlement {
+	return g.refunds.Settlements()
+}
+
+// instrumentError marks card-state failures as invalid-instrument so
+// callers can handle them like any other unusable payment method.
+func instrumentError(err error) error {
+	if errors.Is(err, ErrExpired) || errors.Is(err, ErrVoided) || errors.Is(err, ErrUnknownCard) || errors.Is(err, ErrInvalidCode) {
+		return fmt.Errorf("%w: %w", paymentstrategy.ErrInvalidInstrument, err)
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+package giftcard
+
+import paymentstrategy "strategy-design/payment-strategy"
+
+// Spec: gift-card:code=XXXX-XXXX-XXXX-XXXX (cards live in DefaultStore)
+func init() {
+	paymentstrategy.Register(Method, func(cfg paymentstrategy.Config) (paymentstrategy.PaymentStrategy, error) {
+		code, err := cfg.Require("code")
+		if err != nil {
+			return nil, err
+		}
+		return NewGiftCard(DefaultStore, code)
+	})
+}
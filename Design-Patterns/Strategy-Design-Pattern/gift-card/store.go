@@ -0,0 +1,200 @@
+package giftcard
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+	"time"
+)
+
+var (
+	ErrInvalidCode = errors.New("gift card code is not valid")
+	ErrUnknownCard = errors.New("gift card not found")
+	ErrExpired     = errors.New("gift card has expired")
+	ErrVoided      = errors.New("gift card has been voided")
+)
+
+type Card struct {
+	Code      string
+	Initial   money.Money
+	Balance   money.Money
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	Voided    bool
+}
+
+type Redemption struct {
+	Reference string
+	Amount    money.Money
+	Timestamp time.Time
+}
+
+type card struct {
+	Card
+	redemptions []Redemption
+}
+
+type Store struct {
+	mu    sync.Mutex
+	cards map[string]*card
+	now   func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		cards: make(map[string]*card),
+		now:   time.Now,
+	}
+}
+
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Issue creates a card loaded with amount that stops working after
+// validFor; a zero validFor never expires.
+func (s *Store) Issue(amount money.Money, validFor time.Duration) (Card, error) {
+	if !amount.IsPositive() {
+		return Card{}, paymentstrategy.ErrInvalidAmount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var code string
+	for {
+		var err error
+		if code, err = NewCode(); err != nil {
+			return Card{}, err
+		}
+		if _, dup := s.cards[NormalizeCode(code)]; !dup {
+			break
+		}
+	}
+	now := s.now()
+	c := &card{Card: Card{Code: code, Initial: amount, Balance: amount, IssuedAt: now}}
+	if validFor > 0 {
+		c.ExpiresAt = now.Add(validFor)
+	}
+	s.cards[NormalizeCode(code)] = c
+	return c.Card, nil
+}
+
+func (s *Store) Lookup(code string) (Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.find(code)
+	if err != nil {
+		return Card{}, err
+	}
+	return c.Card, nil
+}
+
+// Balance returns the spendable balance of a live card.
+func (s *Store) Balance(code string) (money.Money, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.usable(code)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return c.Balance, nil
+}
+
+// Redeem takes amount off the card; a card can be redeemed partially over
+// any number of checkouts until its balance runs out.
+func (s *Store) Redeem(code string, amount money.Money, reference string) (money.Money, error) {
+	if !amount.IsPositive() {
+		return money.Money{}, paymentstrategy.ErrInvalidAmount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.usable(code)
+	if err != nil {
+		return money.Money{}, err
+	}
+	remaining, err := c.Balance.Sub(amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if remaining.IsNegative() {
+		return money.Money{}, fmt.Errorf("%w: gift card has %s, %s needed", paymentstrategy.ErrInsufficientFunds, c.Balance, amount)
+	}
+	c.Balance = remaining
+	c.redemptions = append(c.redemptions, Redemption{Reference: reference, Amount: amount, Timestamp: s.now()})
+	return remaining, nil
+}
+
+// Credit puts amount back on a live card, e.g. for a refund.
+func (s *Store) Credit(code string, amount money.Money, reference string) (money.Money, error) {
+	if !amount.IsPositive() {
+		return money.Money{}, paymentstrategy.ErrInvalidAmount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.usable(code)
+	if err != nil {
+		return money.Money{}, err
+	}
+	balance, err := c.Balance.Add(amount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	c.Balance = balance
+	c.redemptions = append(c.redemptions, Redemption{Reference: reference, Amount: amount.Negate(), Timestamp: s.now()})
+	return balance, nil
+}
+
+// Void cancels a card for good, forfeiting any remaining balance.
+func (s *Store) Void(code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.find(code)
+	if err != nil {
+		return err
+	}
+	if c.Voided {
+		return fmt.Errorf("%w: %s", ErrVoided, mask(code))
+	}
+	c.Voided = true
+	return nil
+}
+
+// Redemptions lists movements on the card, oldest first; credits are
+// negative.
+func (s *Store) Redemptions(code string) ([]Redemption, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, err := s.find(code)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Redemption(nil), c.redemptions...), nil
+}
+
+func (s *Store) find(code string) (*card, error) {
+	if !ValidCode(code) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidCode, code)
+	}
+	c, ok := s.cards[NormalizeCode(code)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCard, mask(code))
+	}
+	return c, nil
+}
+
+func (s *Store) usable(code string) (*card, error) {
+	c, err := s.find(code)
+	if err != nil {
+		return nil, err
+	}
+	if c.Voided {
+		return nil, fmt.Errorf("%w: %s", ErrVoided, mask(code))
+	}
+	if !c.ExpiresAt.IsZero() && !s.now().Before(c.ExpiresAt) {
+		return nil, fmt.Errorf("%w: %s", ErrExpired, mask(code))
+	}
+	return c, nil
+}
@@ -0,0 +1,174 @@
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+	"time"
+)
+
+const (
+	AccountSales   = "revenue:sales"
+	AccountRefunds = "revenue:refunds"
+)
+
+var (
+	ErrUnbalanced   = errors.New("ledger transaction does not balance")
+	ErrEmptyPosting = errors.New("ledger transaction has no postings")
+)
+
+// ClearingAccount holds money a payment method has collected for us but
+// not yet paid out.
+func ClearingAccount(method string) string {
+	return "assets:clearing:" + method
+}
+
+func FeeAccount(method string) string {
+	return "expenses:fees:" + method
+}
+
+type Kind string
+
+const (
+	KindCharge Kind = "charge"
+	KindRefund Kind = "refund"
+	KindFee    Kind = "fee"
+)
+
+// Posting moves Amount into Account: positive amounts are debits, negative
+// amounts credits.
+type Posting struct {
+	Account string
+	Amount  money.Money
+}
+
+type Transaction struct {
+	ID        string
+	Kind      Kind
+	Method    string
+	Reference string
+	Postings  []Posting
+	Timestamp time.Time
+}
+
+// Ledger is an append-only double-entry journal. Every transaction must
+// balance to zero in each currency it touches.
+type Ledger struct {
+	mu           sync.RWMutex
+	transactions []Transaction
+	balances     map[string]map[money.Currency]int64
+}
+
+func New() *Ledger {
+	return &Ledger{
+		balances: make(map[string]map[money.Currency]int64),
+	}
+}
+
+func (l *Ledger) Post(tx Transaction) error {
+	if len(tx.Postings) == 0 {
+		return ErrEmptyPosting
+	}
+	sums := make(map[money.Currency]int64)
+	for _, p := range tx.Postings {
+		sums[p.Amount.Currency()] += p.Amount.Amount()
+	}
+	for currency, sum := range sums {
+		if sum != 0 {
+			return fmt.Errorf("%w: %s %s off by %s", ErrUnbalanced, tx.Kind, tx.Reference, money.New(sum, currency))
+		}
+	}
+	if tx.Timestamp.IsZero() {
+		tx.Timestamp = time.Now()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if tx.ID == "" {
+		tx.ID = fmt.Sprintf("jnl_%06d", len(l.transactions)+1)
+	}
+	for _, p := range tx.Postings {
+		if l.balances[p.Account] == nil {
+			l.balances[p.Account] = make(map[money.Currency]int64)
+		}
+		l.balances[p.Account][p.Amount.Currency()] += p.Amount.Amount()
+	}
+	l.transactions = append(l.transactions, tx)
+	return nil
+}
+
+// RecordCharge books a successful charge: the gross amount is owed to us by
+// the payment method and earned as sales. A non-zero receipt Fee is booked
+// as a separate fee transaction.
+func (l *Ledger) RecordCharge(receipt *paymentstrategy.Receipt) error {
+	err := l.Post(Transaction{
+		Kind:      KindCharge,
+		Method:    receipt.Method,
+		Reference: receipt.TransactionID,
+		Timestamp: receipt.Timestamp,
+		Postings: []Posting{
+			{Account: ClearingAccount(receipt.Method), Amount: receipt.Amount},
+			{Account: AccountSales, Amount: receipt.Amount.Negate()},
+		},
+	})
+	if err != nil || receipt.Fee.IsZero() {
+		return err
+	}
+	return l.RecordFee(receipt.Method, receipt.TransactionID, receipt.Fee)
+}
+
+// RecordRefund books a successful refund against the original charge.
+func (l *Ledger) RecordRefund(refund *paymentstrategy.Receipt) error {
+	return l.Post(Transaction{
+		Kind:      KindRefund,
+		Method:    refund.Method,
+		Reference: refund.OriginalTransactionID,
+		Timestamp: refund.Timestamp,
+		Postings: []Posting{
+			{Account: AccountRefunds, Amount: refund.Amount},
+			{Account: ClearingAccount(refund.Method), Amount: refund.Amount.Negate()},
+		},
+	})
+}
+
+// RecordFee books a processing fee the payment method keeps out of the
+// money it collected for reference.
+func (l *Ledger) RecordFee(method, reference string, fee money.Money) error {
+	return l.Post(Transaction{
+		Kind:      KindFee,
+		Method:    method,
+		Reference: reference,
+		Postings: []Posting{
+			{Account: FeeAccount(method), Amount: fee},
+			{Account: ClearingAccount(method), Amount: fee.Negate()},
+		},
+	})
+}
+
+// Balance returns the debit-positive balance of account in currency; credit
+// accounts such as sales come back negative.
+func (l *Ledger) Balance(account string, currency money.Currency) money.Money {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return money.New(l.balances[account][currency], currency)
+}
+
+func (l *Ledger) Accounts() []string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	accounts := make([]string, 0, len(l.balances))
+	for account := range l.balances {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+	return accounts
+}
+
+func (l *Ledger) Transactions() []Transaction {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]Transaction(nil), l.transactions...)
+}
@@ -0,0 +1,165 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+)
+
+// Settlement is what a payment method reports for one charge.
+type Settlement = paymentstrategy.Settlement
+
+// Collect gathers the settlements reported by strategies, looking through
+// wrappers that expose Unwrap. Strategies that cannot report are skipped
+// and a charge reported twice is kept once.
+func Collect(strategies ...paymentstrategy.PaymentStrategy) []Settlement {
+	var settlements []Settlement
+	seen := make(map[string]bool)
+	for _, strategy := range strategies {
+		for strategy != nil {
+			if reporter, ok := strategy.(paymentstrategy.SettlementReporter); ok {
+				for _, s := range reporter.Settlements() {
+					if !seen[s.TransactionID] {
+						seen[s.TransactionID] = true
+						settlements = append(settlements, s)
+					}
+				}
+				break
+			}
+			wrapper, ok := strategy.(interface {
+				Unwrap() paymentstrategy.PaymentStrategy
+			})
+			if !ok {
+				break
+			}
+			strategy = wrapper.Unwrap()
+		}
+	}
+	return settlements
+}
+
+type Mismatch struct {
+	Method        string
+	TransactionID string
+	Field         string
+	Ledger        money.Money
+	Reported      money.Money
+}
+
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%s %s %s: ledger %s, reported %s", m.Method, m.TransactionID, m.Field, m.Ledger, m.Reported)
+}
+
+type Report struct {
+	Matched []string
+	// Mismatches lists charges where the ledger and the settlement disagree.
+	Mismatches []Mismatch
+	// MissingFromLedger are settled charges the ledger never recorded.
+	MissingFromLedger []Settlement
+	// MissingFromSettlement are ledger charges no settlement mentions.
+	MissingFromSettlement []string
+}
+
+func (r *Report) Clean() bool {
+	return len(r.Mismatches) == 0 && len(r.MissingFromLedger) == 0 && len(r.MissingFromSettlement) == 0
+}
+
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "reconciliation: %d matched, %d mismatched, %d missing from ledger, %d missing from settlement",
+		len(r.Matched), len(r.Mismatches), len(r.MissingFromLedger), len(r.MissingFromSettlement))
+	for _, m := range r.Mismatches {
+		fmt.Fprintf(&b, "\n  mismatch: %s", m)
+	}
+	for _, s := range r.MissingFromLedger {
+		fmt.Fprintf(&b, "\n  not in ledger: %s %s", s.Method, s.TransactionID)
+	}
+	for _, id := range r.MissingFromSettlement {
+		fmt.Fprintf(&b, "\n  not settled: %s", id)
+	}
+	return b.String()
+}
+
+type chargeTotals struct {
+	method               string
+	gross, refunded, fee int64
+	currency             money.Currency
+}
+
+// Reconcile compares what the ledger recorded per charge against the
+// settlements reported by the payment methods.
+func (l *Ledger) Reconcile(settlements []Settlement) *Report {
+	totals := l.chargeTotals()
+	report := &Report{}
+	seen := make(map[string]bool, len(settlements))
+	for _, s := range settlements {
+		seen[s.TransactionID] = true
+		t, ok := totals[s.TransactionID]
+		if !ok {
+			report.MissingFromLedger = append(report.MissingFromLedger, s)
+			continue
+		}
+		before := len(report.Mismatches)
+		report.compare(s, "gross", money.New(t.gross, t.currency), s.Gross)
+		report.compare(s, "refunded", money.New(t.refunded, t.currency), s.Refunded)
+		report.compare(s, "fee", money.New(t.fee, t.currency), s.Fee)
+		if t.method != s.Method {
+			report.Mismatches = append(report.Mismatches, Mismatch{Method: s.Method, TransactionID: s.TransactionID, Field: "method " + t.method})
+		}
+		if len(report.Mismatches) == before {
+			report.Matched = append(report.Matched, s.TransactionID)
+		}
+	}
+	for id := range totals {
+		if !seen[id] {
+			report.MissingFromSettlement = append(report.MissingFromSettlement, id)
+		}
+	}
+	sort.Strings(report.MissingFromSettlement)
+	return report
+}
+
+func (r *Report) compare(s Settlement, field string, ledger, reported money.Money) {
+	if reported.Currency() == "" {
+		reported = money.Zero(ledger.Currency())
+	}
+	if !ledger.Equal(reported) {
+		r.Mismatches = append(r.Mismatches, Mismatch{
+			Method:        s.Method,
+			TransactionID: s.TransactionID,
+			Field:         field,
+			Ledger:        ledger,
+			Reported:      reported,
+		})
+	}
+}
+
+func (l *Ledger) chargeTotals() map[string]*chargeTotals {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	totals := make(map[string]*chargeTotals)
+	for _, tx := range l.transactions {
+		t, ok := totals[tx.Reference]
+		if !ok {
+			t = &chargeTotals{method: tx.Method}
+			totals[tx.Reference] = t
+		}
+		for _, p := range tx.Postings {
+			if p.Account != ClearingAccount(tx.Method) {
+				continue
+			}
+			t.currency = p.Amount.Currency()
+			switch tx.Kind {
+			case KindCharge:
+				t.gross += p.Amount.Amount()
+			case KindRefund:
+				t.refunded -= p.Amount.Amount()
+			case KindFee:
+				t.fee -= p.Amount.Amount()
+			}
+		}
+	}
+	return totals
+}
@@ -1,30 +1,356 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http/httptest"
+	"os"
 	"strategy-design/bitcoin"
 	creditcard "strategy-design/credit-card"
+	"strategy-design/discount"
+	"strategy-design/fraud"
+	giftcard "strategy-design/gift-card"
+	"strategy-design/ledger"
+	"strategy-design/middleware"
+	"strategy-design/money"
+	"strategy-design/order"
+	_ "strategy-design/payment-methods"
+	paymentstrategy "strategy-design/payment-strategy"
 	"strategy-design/paypal"
+	mockgateway "strategy-design/paypal/mock-gateway"
+	retrystrategy "strategy-design/retry-strategy"
 	shoppingcart "strategy-design/shopping-cart"
+	"strategy-design/subscription"
+	"strategy-design/tax"
+	"strategy-design/wallet"
+	"time"
 )
 
 func main() {
-	creditCardPayment := creditcard.NewCreditCard("MasterCard", "1234-5678-9012-3456")
-	paypalPayment := paypal.NewPaypal("navneet@shukla.com")
-	bitcoinPayment := bitcoin.NewBitcoin("1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa")
+	ctx := context.Background()
+
+	// Payment methods are built from config strings through the registry
+	specs := []string{
+		"credit-card:name=Navneet Shukla,number=5555-5555-5555-4444,expiry=12/2030,cvv=123,settle=INR,rates=rates.json",
+		"paypal:email=navneet@shukla.com",
+		"bitcoin:wallet=1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa,rates=rates.json",
+	}
+	strategies := make([]paymentstrategy.PaymentStrategy, 0, len(specs))
+	for _, spec := range specs {
+		strategy, err := paymentstrategy.New(spec)
+		if err != nil {
+			fmt.Println("Invalid payment method:", err)
+			return
+		}
+		strategies = append(strategies, strategy)
+	}
+	creditCardPayment, paypalPayment, bitcoinPayment := strategies[0], strategies[1], strategies[2]
 
 	// Create shopping cart with credit card payment
-	cart := shoppingcart.NewShoppingCart(creditCardPayment)
-	cart.Checkout(123.45)
+	cart := shoppingcart.NewShoppingCart(creditCardPayment, money.USD)
+	books := ledger.New()
+	cart.SetLedger(books)
+	cart.AddItem("BOOK-001", "Go Programming", money.MustParse("45.50", money.USD), 2)
+	cart.AddItem("PEN-010", "Gel Pen", money.MustParse("2.15", money.USD), 15)
+	printResult(cart.Checkout(ctx))
 
 	// Switch to PayPal
 	cart.SetPaymentMethod(paypalPayment)
-	cart.Checkout(67.89)
+	cart.UpdateQuantity("PEN-010", 5)
+	printResult(cart.Checkout(ctx))
+
+	// A retried checkout with the same key is not charged twice
+	printResult(cart.CheckoutWithKey(ctx, "order-42"))
+	printResult(cart.CheckoutWithKey(ctx, "order-42"))
+
+	// Coupons are applied before the payment strategy is charged
+	coupons := discount.NewEngine()
+	coupons.Add(discount.NewPercentage("SAVE10", 10))
+	coupons.Add(discount.NewBuyXGetY("PENS-2+1", "PEN-010", 2, 1))
+	cart.SetDiscountEngine(coupons)
+	cart.ApplyCoupon("SAVE10")
+	cart.ApplyCoupon("PENS-2+1")
+	printResult(cart.Checkout(ctx))
+	cart.RemoveCoupon("SAVE10")
+	cart.RemoveCoupon("PENS-2+1")
+
+	// Sales tax for the shipping region is added on top of the subtotal
+	taxes := tax.NewSelector()
+	taxes.Register("IN", tax.NewGST("MH"))
+	taxes.Register("US", tax.NewUSSalesTax())
+	taxes.Register("DE", tax.NewVAT(19, true))
+	cart.SetTaxSelector(taxes)
+	cart.SetShippingRegion(tax.Region{Country: "US", State: "CA"})
+	cart.SetTaxClass("BOOK-001", "exempt")
+	printResult(cart.Checkout(ctx))
 
 	// Switch to Bitcoin
 	cart.SetPaymentMethod(bitcoinPayment)
-	cart.Checkout(999.99)
+	cart.AddItem("LAPTOP-100", "Laptop", money.MustParse("899.99", money.USD), 1)
+	cart.RemoveItem("BOOK-001")
+	btcReceipt, err := cart.Checkout(ctx)
+	printResult(btcReceipt, err)
+
+	// Watch the chain until the invoice is deep enough to settle; the cart
+	// books the charge only then
+	if btc, ok := bitcoinPayment.(*bitcoin.Bitcoin); ok && err == nil {
+		btcReceipt.OnSettle(printResult)
+		invoice, _ := btc.Invoice(btcReceipt.TransactionID)
+		btc.Observe(invoice.TransactionID, invoice.Expected)
+		for !invoice.Final() {
+			if invoice, err = btc.Confirm(invoice.TransactionID); err != nil {
+				break
+			}
+			fmt.Printf("Bitcoin invoice %s: %d/%d confirmation(s), %s\n", invoice.TransactionID, invoice.Confirmations, invoice.Required, invoice.State)
+		}
+	}
+
+	// Split the bill between PayPal and the card
+	split, err := cart.CheckoutSplit(ctx,
+		shoppingcart.NewTender(paypalPayment, money.MustParse("100.00", money.USD)),
+		shoppingcart.RemainderTender(creditCardPayment),
+	)
+	if err != nil {
+		fmt.Println("Split checkout failed:", err)
+	} else {
+		for _, leg := range split.Legs {
+			fmt.Println("Split leg:", leg)
+		}
+	}
+
+	// Card with retries, falling back to PayPal
+	cart.SetPaymentMethod(retrystrategy.NewRetryStrategy(retrystrategy.DefaultPolicy(), creditCardPayment, paypalPayment))
+	printResult(cart.Checkout(ctx))
+
+	// PayPal over HTTP against the bundled mock gateway; the first request
+	// gets a 503 and is retried
+	gateway := mockgateway.NewServer()
+	server := httptest.NewServer(gateway)
+	defer server.Close()
+	gateway.FailNext(1)
+	httpPaypal := paypal.NewPaypalWithGateway("navneet@shukla.com", paypal.NewHTTPGateway(server.URL, nil))
+	cart.SetPaymentMethod(retrystrategy.NewRetryStrategy(retrystrategy.DefaultPolicy(), httpPaypal))
+	printResult(cart.Checkout(ctx))
+	gateway.Decline("navneet@shukla.com", paypal.ReasonInsufficientFunds)
+	printResult(cart.Checkout(ctx))
+
+	// A slow gateway is cut off by the request deadline
+	gateway.Allow("navneet@shukla.com")
+	gateway.SetLatency(200 * time.Millisecond)
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	printResult(cart.Checkout(timeoutCtx))
+	cancel()
+	gateway.SetLatency(0)
+
+	// Pay part from a wallet balance and the rest by card
+	wallet.DefaultStore.TopUp("cust-42", money.MustParse("50.00", money.USD), "signup bonus")
+	walletPayment, _ := paymentstrategy.New("wallet:customer=cust-42")
+	available, _, _ := wallet.DefaultStore.Balance("cust-42")
+	split, err = cart.CheckoutSplit(ctx,
+		shoppingcart.NewTender(walletPayment, available),
+		shoppingcart.RemainderTender(creditCardPayment),
+	)
+	if err != nil {
+		fmt.Println("Split checkout failed:", err)
+	}
+	available, _, _ = wallet.DefaultStore.Balance("cust-42")
+	fmt.Println("Wallet balance:", available)
+
+	// Gift cards can be redeemed partially across checkouts
+	gift, _ := giftcard.DefaultStore.Issue(money.MustParse("30.00", money.USD), 365*24*time.Hour)
+	giftPayment, _ := paymentstrategy.New("gift-card:code=" + gift.Code)
+	cart.SetPaymentMethod(giftPayment)
+	cart.UpdateQuantity("LAPTOP-100", 0)
+	printResult(cart.Checkout(ctx))
+	printResult(cart.Checkout(ctx))
+	balance, _ := giftcard.DefaultStore.Balance(gift.Code)
+	fmt.Println("Gift card", gift.Code, "balance:", balance)
+	giftcard.DefaultStore.Void(gift.Code)
+	printResult(cart.Checkout(ctx))
+
+	// Authorize at checkout, capture when the order ships; a second capture
+	// is refused, and a wallet hold is voided when the order is cancelled
+	cart.SetPaymentMethod(creditCardPayment)
+	if auth, err := cart.AuthorizeCheckout(ctx); err != nil {
+		fmt.Println("Authorization failed:", err)
+	} else {
+		fmt.Println("Authorization:", auth)
+		printResult(cart.CaptureShipment(ctx, auth.ID, money.MustParse("5.00", money.USD)))
+		printResult(cart.CaptureShipmentFull(ctx, auth.ID))
+	}
+	wallet.DefaultStore.TopUp("cust-42", money.MustParse("20.00", money.USD), "refund credit")
+	cart.SetPaymentMethod(walletPayment)
+	if auth, err := cart.AuthorizeCheckout(ctx); err == nil {
+		_, held, _ := wallet.DefaultStore.Balance("cust-42")
+		fmt.Println("Wallet held:", held)
+		if err := cart.VoidAuthorization(ctx, auth.ID); err != nil {
+			fmt.Println("Void failed:", err)
+		}
+		holds, _ := wallet.DefaultStore.History("cust-42", wallet.Hold, wallet.Release)
+		for _, entry := range holds {
+			fmt.Println("Wallet entry:", entry)
+		}
+	}
+
+	// An order moves through guarded states and keeps its history
+	cart.SetPaymentMethod(paypalPayment)
+	if placed, err := order.New(cart); err != nil {
+		fmt.Println("Order failed:", err)
+	} else {
+		placed.Pay(ctx)
+		placed.Fulfil(ctx)
+		placed.Refund(ctx, money.MustParse("2.00", money.USD))
+		if err := placed.Cancel(ctx, "customer changed their mind"); err != nil {
+			fmt.Println("Cancel refused:", err)
+		}
+		for _, t := range placed.History() {
+			fmt.Println("Order", placed.ID(), t.From, "->", t.To+":", t.Note)
+		}
+	}
+
+	// Cancelling an order still waiting on chain closes its invoice
+	cart.SetPaymentMethod(bitcoinPayment)
+	if placed, err := order.New(cart); err == nil {
+		placed.Pay(ctx)
+		if err := placed.Cancel(ctx, "customer chose another method"); err != nil {
+			fmt.Println("Cancel refused:", err)
+		}
+		if btc, ok := bitcoinPayment.(*bitcoin.Bitcoin); ok && placed.Payment() != nil {
+			invoice, _ := btc.Invoice(placed.Payment().TransactionID)
+			fmt.Println("Order", placed.ID(), placed.State()+", invoice", invoice.State)
+		}
+	}
+
+	// Subscriptions renew on a schedule; a failed renewal is retried until
+	// it succeeds or dunning gives up
+	clock := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+	billing := subscription.NewScheduler(subscription.DefaultDunning())
+	billing.SetClock(func() time.Time { return clock })
+	wallet.DefaultStore.TopUp("cust-7", money.MustParse("25.00", money.USD), "gift")
+	subscriber, _ := paymentstrategy.New("wallet:customer=cust-7")
+	basic := subscription.Plan{ID: "basic", Name: "Basic", Price: money.MustParse("9.99", money.USD), Interval: subscription.Monthly}
+	pro := subscription.Plan{ID: "pro", Name: "Pro", Price: money.MustParse("19.99", money.USD), Interval: subscription.Monthly}
+	if sub, err := billing.Subscribe(ctx, "cust-7", basic, subscriber); err != nil {
+		fmt.Println("Subscribe failed:", err)
+	} else {
+		clock = clock.AddDate(0, 0, 14)
+		billing.ChangePlan(ctx, sub.ID, pro)
+		clock = sub.PeriodEnd
+		billing.Run(ctx)
+		wallet.DefaultStore.TopUp("cust-7", money.MustParse("20.00", money.USD), "top-up")
+		clock = clock.Add(24 * time.Hour)
+		billing.Run(ctx)
+		sub, _ = billing.Get(sub.ID)
+		for _, invoice := range sub.Invoices {
+			fmt.Println("Invoice:", invoice)
+		}
+		fmt.Println("Subscription:", sub)
+	}
+
+	// Card purchases can be split into monthly installments and closed early
+	if card, ok := creditCardPayment.(*creditcard.CreditCard); ok {
+		price := money.MustParse("600.00", money.USD)
+		options, _ := card.EMIOptions(price)
+		for _, option := range options {
+			fmt.Println("EMI option:", option)
+		}
+		if emi, _, err := card.StartEMI(ctx, price, 6); err != nil {
+			fmt.Println("EMI failed:", err)
+		} else {
+			emi.PayNext(ctx)
+			emi.Foreclose(ctx)
+			for _, row := range emi.Schedule() {
+				charges := fmt.Sprintf("principal %s, interest %s", row.Principal, row.Interest)
+				if !row.ForeclosureCharge.IsZero() {
+					charges += ", foreclosure " + row.ForeclosureCharge.String()
+				}
+				fmt.Printf("EMI %d %s: %s (%s), balance %s\n", row.Number, row.Status, row.Amount, charges, row.Balance)
+			}
+		}
+	}
+
+	// Pick whichever method costs least in fees for this cart
+	if selection, err := cart.SelectCheapest(creditCardPayment, paypalPayment, bitcoinPayment, giftPayment); err != nil {
+		fmt.Println("No eligible method:", err)
+	} else {
+		fmt.Println(selection)
+		printResult(cart.Checkout(ctx))
+	}
+
+	// Partial refund of the first card charge, then try to refund too much
+	first := cart.Payments()[0]
+	printResult(cart.Refund(ctx, first.TransactionID, money.MustParse("23.25", money.USD)))
+	printResult(cart.Refund(ctx, first.TransactionID, money.MustParse("500.00", money.USD)))
+	printResult(cart.RefundFull(ctx, first.TransactionID))
+
+	// Every charge and refund made through the cart is in the ledger;
+	// reconcile it against what the payment methods themselves report. The
+	// EMI installments went straight to the card, so the ledger lacks them
+	fmt.Println("Sales:", books.Balance(ledger.AccountSales, money.USD), "Refunds:", books.Balance(ledger.AccountRefunds, money.USD))
+	settlements := ledger.Collect(creditCardPayment, paypalPayment, bitcoinPayment, httpPaypal, walletPayment, giftPayment)
+	fmt.Println(books.Reconcile(settlements))
+
+	// Charges are screened against fraud rules before reaching a strategy
+	if rules, err := fraud.LoadFile("fraud.json"); err != nil {
+		fmt.Println("Fraud rules:", err)
+	} else {
+		cart.SetFraudScreen(rules)
+		cart.SetPaymentMethod(paypal.NewPaypal("chargeback@example.com"))
+		printResult(cart.Checkout(ctx))
+		rupay, _ := paymentstrategy.New("credit-card:name=Asha Rao,number=6521-5000-0000-0014,expiry=08/2029,cvv=321")
+		cart.SetPaymentMethod(rupay)
+		printResult(cart.Checkout(ctx))
+		var screening *fraud.ScreeningError
+		if _, err := cart.Checkout(ctx); errors.As(err, &screening) {
+			fmt.Println("Fraud decision:", screening.Result.Decision, "-", len(screening.Result.Reasons), "reason(s)")
+		}
+		cart.SetPaymentMethod(paypalPayment)
+		printResult(cart.Checkout(ctx))
+	}
+
+	// Middleware adds structured logs, counters and trace spans around any
+	// strategy without changing it
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "latency" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	metrics := middleware.NewMetrics()
+	spans := middleware.NewSpanRecorder()
+	cart.SetPaymentMethod(middleware.Wrap(paypalPayment, middleware.Logging(logger), metrics.Middleware(), middleware.Tracing(spans)))
+	if receipt, err := cart.Checkout(ctx); err == nil {
+		cart.Refund(ctx, receipt.TransactionID, money.MustParse("1.00", money.USD))
+	}
+	for _, stat := range metrics.Snapshot() {
+		fmt.Printf("Metric: %s %s %s x%d\n", stat.Method, stat.Op, stat.Outcome, stat.Count)
+	}
+	for _, span := range spans.Spans() {
+		fmt.Println("Span:", span)
+	}
+
+	// Invalid cards are rejected up front
+	if _, err := paymentstrategy.New("credit-card:number=1234-5678-9012-3456,expiry=12/2030,cvv=123"); err != nil {
+		fmt.Println("Invalid card:", err)
+	}
 
 	// Demonstrate nil payment handling
 	cart.SetPaymentMethod(nil)
-	cart.Checkout(100.00)
+	printResult(cart.Checkout(ctx))
+}
+
+func printResult(receipt *paymentstrategy.Receipt, err error) {
+	if err != nil && receipt != nil {
+		fmt.Printf("Failed (%s): %v\n", receipt.Status, err)
+		return
+	}
+	if err != nil {
+		fmt.Println("Failed:", err)
+		return
+	}
+	fmt.Println("Receipt:", receipt)
 }
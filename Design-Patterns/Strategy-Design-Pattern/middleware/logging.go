@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Logging writes one structured record per call: Info when it went
+// through, Warn when the strategy declined or failed it.
+func Logging(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call Call) Result {
+			start := time.Now()
+			r := next(ctx, call)
+			attrs := []slog.Attr{
+				slog.String("op", string(call.Op)),
+				slog.String("method", call.Method),
+				slog.String("amount", call.Amount.String()),
+				slog.String("outcome", r.Outcome()),
+				slog.Duration("latency", time.Since(start)),
+			}
+			if r.Receipt != nil {
+				attrs = append(attrs, slog.String("transaction", r.Receipt.TransactionID))
+			}
+			if r.Authorization != nil {
+				attrs = append(attrs, slog.String("authorization", r.Authorization.ID))
+			}
+			if call.Original != nil {
+				attrs = append(attrs, slog.String("original", call.Original.TransactionID))
+			}
+			level := slog.LevelInfo
+			if r.Err != nil {
+				level = slog.LevelWarn
+				attrs = append(attrs, slog.String("error", r.Err.Error()))
+			}
+			logger.LogAttrs(ctx, level, "payment "+string(call.Op), attrs...)
+			return r
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Stat counts calls for one method, operation and outcome.
+type Stat struct {
+	Method       string
+	Op           Op
+	Outcome      string
+	Count        int64
+	TotalLatency time.Duration
+	MaxLatency   time.Duration
+}
+
+func (s Stat) MeanLatency() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Count)
+}
+
+func (s Stat) String() string {
+	return fmt.Sprintf("%s %s %s: %d call(s), mean %s, max %s", s.Method, s.Op, s.Outcome, s.Count, s.MeanLatency(), s.MaxLatency)
+}
+
+// Metrics keeps in-process latency and outcome counters. One Metrics can
+// be shared by the middleware of several strategies.
+type Metrics struct {
+	mu    sync.Mutex
+	stats map[statKey]*Stat
+}
+
+type statKey struct {
+	method  string
+	op      Op
+	outcome string
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{stats: make(map[statKey]*Stat)}
+}
+
+func (m *Metrics) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call Call) Result {
+			start := time.Now()
+			r := next(ctx, call)
+			m.observe(call, r.Outcome(), time.Since(start))
+			return r
+		}
+	}
+}
+
+func (m *Metrics) observe(call Call, outcome string, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	key := statKey{call.Method, call.Op, outcome}
+	s, ok := m.stats[key]
+	if !ok {
+		s = &Stat{Method: call.Method, Op: call.Op, Outcome: outcome}
+		m.stats[key] = s
+	}
+	s.Count++
+	s.TotalLatency += latency
+	s.MaxLatency = max(s.MaxLatency, latency)
+}
+
+// Snapshot returns the counters sorted by method, operation and outcome.
+func (m *Metrics) Snapshot() []Stat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]Stat, 0, len(m.stats))
+	for _, s := range m.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		if a.Op != b.Op {
+			return a.Op < b.Op
+		}
+		return a.Outcome < b.Outcome
+	})
+	return stats
+}
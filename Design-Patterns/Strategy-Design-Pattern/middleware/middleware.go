@@ -0,0 +1,246 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// Op names the strategy call being made.
+type Op string
+
+const (
+	OpPay       Op = "pay"
+	OpRefund    Op = "refund"
+	OpAuthorize Op = "authorize"
+	OpCapture   Op = "capture"
+	OpVoid      Op = "void"
+)
+
+// Call describes one call into a strategy. Original is set for refunds
+// and Authorization for captures and voids.
+type Call struct {
+	Op            Op
+	Method        string
+	Amount        money.Money
+	Original      *paymentstrategy.Receipt
+	Authorization *paymentstrategy.Authorization
+}
+
+// Result is what the strategy returned. Receipt is set by pay, refund and
+// capture, Authorization by authorize.
+type Result struct {
+	Receipt       *paymentstrategy.Receipt
+	Authorization *paymentstrategy.Authorization
+	Err           error
+}
+
+// Outcome summarises a result in one word: the receipt status if there
+// is one, otherwise "ok" or "error".
+func (r Result) Outcome() string {
+	switch {
+	case r.Receipt != nil:
+		return string(r.Receipt.Status)
+	case r.Err != nil:
+		return "error"
+	}
+	return "ok"
+}
+
+type Handler func(ctx context.Context, call Call) Result
+
+// Middleware wraps a handler with behaviour of its own, calling next to
+// reach the strategy.
+type Middleware func(next Handler) Handler
+
+// Wrap decorates strategy with mws, the first being outermost. The result
+// implements Refunder, Authorizer and FeeQuoter exactly when strategy
+// does, passes an authorizer's Authorizations book through, and forwards
+// Identify and CanPay, so carts, selectors and fraud screening see the
+// same strategy they would without the middleware.
+func Wrap(strategy paymentstrategy.PaymentStrategy, mws ...Middleware) paymentstrategy.PaymentStrategy {
+	w := &wrapped{inner: strategy, method: methodOf(strategy)}
+	w.handle = w.dispatch
+	for i := len(mws) - 1; i >= 0; i-- {
+		w.handle = mws[i](w.handle)
+	}
+	_, refunds := strategy.(paymentstrategy.Refunder)
+	_, authorizes := strategy.(paymentstrategy.Authorizer)
+	_, quotes := strategy.(paymentstrategy.FeeQuoter)
+	r, a, f := refunder{w}, authorizer{w}, feeQuoter{w}
+	switch {
+	case refunds && authorizes && quotes:
+		return struct {
+			*wrapped
+			refunder
+			authorizer
+			feeQuoter
+		}{w, r, a, f}
+	case refunds && authorizes:
+		return struct {
+			*wrapped
+			refunder
+			authorizer
+		}{w, r, a}
+	case refunds && quotes:
+		return struct {
+			*wrapped
+			refunder
+			feeQuoter
+		}{w, r, f}
+	case authorizes && quotes:
+		return struct {
+			*wrapped
+			authorizer
+			feeQuoter
+		}{w, a, f}
+	case refunds:
+		return struct {
+			*wrapped
+			refunder
+		}{w, r}
+	case authorizes:
+		return struct {
+			*wrapped
+			authorizer
+		}{w, a}
+	case quotes:
+		return struct {
+			*wrapped
+			feeQuoter
+		}{w, f}
+	}
+	return w
+}
+
+// Unwrap returns the strategy inside any middleware.
+func Unwrap(strategy paymentstrategy.PaymentStrategy) paymentstrategy.PaymentStrategy {
+	for {
+		u, ok := strategy.(interface {
+			Unwrap() paymentstrategy.PaymentStrategy
+		})
+		if !ok {
+			return strategy
+		}
+		strategy = u.Unwrap()
+	}
+}
+
+type wrapped struct {
+	inner  paymentstrategy.PaymentStrategy
+	method string
+	handle Handler
+}
+
+func (w *wrapped) Unwrap() paymentstrategy.PaymentStrategy {
+	return w.inner
+}
+
+func (w *wrapped) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	r := w.handle(ctx, Call{Op: OpPay, Method: w.method, Amount: amount})
+	return r.Receipt, r.Err
+}
+
+func (w *wrapped) Identify() paymentstrategy.Instrument {
+	if id, ok := w.inner.(paymentstrategy.Identifier); ok {
+		return id.Identify()
+	}
+	return paymentstrategy.Instrument{}
+}
+
+func (w *wrapped) CanPay(amount money.Money) error {
+	if checker, ok := w.inner.(paymentstrategy.AvailabilityChecker); ok {
+		return checker.CanPay(amount)
+	}
+	return nil
+}
+
+func (w *wrapped) refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	r := w.handle(ctx, Call{Op: OpRefund, Method: w.method, Amount: amount, Original: original})
+	return r.Receipt, r.Err
+}
+
+func (w *wrapped) authorize(ctx context.Context, amount money.Money) (*paymentstrategy.Authorization, error) {
+	r := w.handle(ctx, Call{Op: OpAuthorize, Method: w.method, Amount: amount})
+	return r.Authorization, r.Err
+}
+
+func (w *wrapped) capture(ctx context.Context, auth *paymentstrategy.Authorization, amount money.Money) (*paymentstrategy.Receipt, error) {
+	r := w.handle(ctx, Call{Op: OpCapture, Method: w.method, Amount: amount, Authorization: auth})
+	return r.Receipt, r.Err
+}
+
+func (w *wrapped) void(ctx context.Context, auth *paymentstrategy.Authorization) error {
+	r := w.handle(ctx, Call{Op: OpVoid, Method: w.method, Amount: auth.Amount, Authorization: auth})
+	return r.Err
+}
+
+// dispatch is the innermost handler: it makes the actual strategy call.
+func (w *wrapped) dispatch(ctx context.Context, call Call) Result {
+	var r Result
+	switch call.Op {
+	case OpPay:
+		r.Receipt, r.Err = w.inner.Pay(ctx, call.Amount)
+	case OpRefund:
+		r.Receipt, r.Err = w.inner.(paymentstrategy.Refunder).Refund(ctx, call.Original, call.Amount)
+	case OpAuthorize:
+		r.Authorization, r.Err = w.inner.(paymentstrategy.Authorizer).Authorize(ctx, call.Amount)
+	case OpCapture:
+		r.Receipt, r.Err = w.inner.(paymentstrategy.Authorizer).Capture(ctx, call.Authorization, call.Amount)
+	case OpVoid:
+		r.Err = w.inner.(paymentstrategy.Authorizer).Void(ctx, call.Authorization)
+	default:
+		r.Err = fmt.Errorf("middleware: unknown operation %q", call.Op)
+	}
+	return r
+}
+
+// refunder, authorizer and feeQuoter each add one optional interface;
+// Wrap embeds the ones the inner strategy has.
+type refunder struct{ w *wrapped }
+
+func (r refunder) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	return r.w.refund(ctx, original, amount)
+}
+
+type authorizer struct{ w *wrapped }
+
+func (a authorizer) Authorize(ctx context.Context, amount money.Money) (*paymentstrategy.Authorization, error) {
+	return a.w.authorize(ctx, amount)
+}
+
+func (a authorizer) Capture(ctx context.Context, auth *paymentstrategy.Authorization, amount money.Money) (*paymentstrategy.Receipt, error) {
+	return a.w.capture(ctx, auth, amount)
+}
+
+func (a authorizer) Void(ctx context.Context, auth *paymentstrategy.Authorization) error {
+	return a.w.void(ctx, auth)
+}
+
+// Authorizations returns the inner strategy's book, or nil if it keeps
+// none.
+func (a authorizer) Authorizations() *paymentstrategy.AuthorizationBook {
+	if book, ok := a.w.inner.(interface {
+		Authorizations() *paymentstrategy.AuthorizationBook
+	}); ok {
+		return book.Authorizations()
+	}
+	return nil
+}
+
+type feeQuoter struct{ w *wrapped }
+
+func (f feeQuoter) Fees() paymentstrategy.FeeModel {
+	return f.w.inner.(paymentstrategy.FeeQuoter).Fees()
+}
+
+// methodOf names a strategy for logs and metrics.
+func methodOf(strategy paymentstrategy.PaymentStrategy) string {
+	if id, ok := strategy.(paymentstrategy.Identifier); ok {
+		if method := id.Identify().Method; method != "" {
+			return method
+		}
+	}
+	return fmt.Sprintf("%T", strategy)
+}
@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracer starts spans. It is small enough to adapt to OpenTelemetry or
+// any other tracing library without this package depending on one.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span)
+}
+
+type Span interface {
+	SetAttributes(attrs ...slog.Attr)
+	End(err error)
+}
+
+// Tracing opens a span around each call. The span's context is passed on,
+// so a strategy that traces its own gateway calls nests under it.
+func Tracing(tracer Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call Call) Result {
+			ctx, span := tracer.Start(ctx, "payment."+string(call.Op),
+				slog.String("payment.method", call.Method),
+				slog.String("payment.amount", call.Amount.String()),
+			)
+			r := next(ctx, call)
+			span.SetAttributes(slog.String("payment.outcome", r.Outcome()))
+			if r.Receipt != nil {
+				span.SetAttributes(slog.String("payment.transaction", r.Receipt.TransactionID))
+			}
+			span.End(r.Err)
+			return r
+		}
+	}
+}
+
+// SpanRecorder is a Tracer that keeps finished spans in memory, for
+// debugging and demos.
+type SpanRecorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+type RecordedSpan struct {
+	Name     string
+	Attrs    []slog.Attr
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+func (s RecordedSpan) String() string {
+	attrs := make([]string, 0, len(s.Attrs))
+	for _, a := range s.Attrs {
+		attrs = append(attrs, a.String())
+	}
+	status := "ok"
+	if s.Err != nil {
+		status = "error: " + s.Err.Error()
+	}
+	return fmt.Sprintf("%s [%s] %s", s.Name, strings.Join(attrs, " "), status)
+}
+
+func NewSpanRecorder() *SpanRecorder {
+	return &SpanRecorder{}
+}
+
+func (t *SpanRecorder) Start(ctx context.Context, name string, attrs ...slog.Attr) (context.Context, Span) {
+	return ctx, &recordingSpan{recorder: t, span: RecordedSpan{Name: name, Attrs: attrs, Start: time.Now()}}
+}
+
+func (t *SpanRecorder) Spans() []RecordedSpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]RecordedSpan(nil), t.spans...)
+}
+
+type recordingSpan struct {
+	recorder *SpanRecorder
+	span     RecordedSpan
+}
+
+func (s *recordingSpan) SetAttributes(attrs ...slog.Attr) {
+	s.span.Attrs = append(s.span.Attrs, attrs...)
+}
+
+func (s *recordingSpan) End(err error) {
+	s.span.Duration = time.Since(s.span.Start)
+	s.span.Err = err
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.recorder.spans = append(s.recorder.spans, s.span)
+}
@@ -0,0 +1,46 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Currency string
+
+const (
+	INR Currency = "INR"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	JPY Currency = "JPY"
+	KWD Currency = "KWD"
+
+	// BTC is not an ISO 4217 code; its minor unit is the satoshi.
+	BTC Currency = "BTC"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// minorUnits holds the ISO 4217 exponent of each supported currency.
+var minorUnits = map[Currency]int{
+	INR: 2,
+	USD: 2,
+	EUR: 2,
+	GBP: 2,
+	JPY: 0,
+	KWD: 3,
+	BTC: 8,
+}
+
+func (c Currency) Valid() bool {
+	_, ok := minorUnits[c]
+	return ok
+}
+
+func (c Currency) MinorUnits() (int, error) {
+	exp, ok := minorUnits[c]
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownCurrency, string(c))
+	}
+	return exp, nil
+}
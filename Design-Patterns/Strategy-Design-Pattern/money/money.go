@@ -0,0 +1,278 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Money is an amount in the minor units (cents, paise, ...) of a currency.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+func New(minor int64, currency Currency) Money {
+	return Money{amount: minor, currency: currency}
+}
+
+func Zero(currency Currency) Money {
+	return Money{currency: currency}
+}
+
+// Parse reads a decimal string such as "12.345" and rounds it half away
+// from zero to the currency's minor unit.
+func Parse(s string, currency Currency) (Money, error) {
+	exp, err := currency.MinorUnits()
+	if err != nil {
+		return Money{}, err
+	}
+	s = strings.TrimSpace(s)
+	// At most one sign; anything after it must be digits.
+	neg := false
+	unsigned := s
+	if rest, ok := strings.CutPrefix(s, "-"); ok {
+		neg, unsigned = true, rest
+	} else if rest, ok := strings.CutPrefix(s, "+"); ok {
+		unsigned = rest
+	}
+	whole, frac, _ := strings.Cut(unsigned, ".")
+	if whole == "" && frac == "" {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if whole == "" {
+		whole = "0"
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	roundUp := false
+	if len(frac) > exp {
+		roundUp = frac[exp] >= '5'
+		frac = frac[:exp]
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+	minor, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if roundUp {
+		minor++
+	}
+	if neg {
+		minor = -minor
+	}
+	return New(minor, currency), nil
+}
+
+func MustParse(s string, currency Currency) Money {
+	m, err := Parse(s, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func FromMajor(major float64, currency Currency) (Money, error) {
+	return Parse(strconv.FormatFloat(major, 'f', -1, 64), currency)
+}
+
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency == other.currency
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return New(m.amount+other.amount, m.currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return New(m.amount-other.amount, m.currency), nil
+}
+
+// Cmp returns -1, 0 or +1 depending on whether m is less than, equal to or
+// greater than other.
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.assertSameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (m Money) Equal(other Money) bool {
+	return m == other
+}
+
+func (m Money) Negate() Money {
+	return New(-m.amount, m.currency)
+}
+
+func (m Money) Multiply(n int64) Money {
+	return New(m.amount*n, m.currency)
+}
+
+// Scale multiplies by num/den, rounding half away from zero.
+func (m Money) Scale(num, den int64) Money {
+	r := new(big.Rat).Mul(new(big.Rat).SetInt64(m.amount), big.NewRat(num, den))
+	return New(roundRat(r), m.currency)
+}
+
+// ScaleRat multiplies by r, rounding half away from zero, for factors
+// that do not fit in an int64 fraction.
+func (m Money) ScaleRat(r *big.Rat) Money {
+	return New(roundRat(new(big.Rat).Mul(new(big.Rat).SetInt64(m.amount), r)), m.currency)
+}
+
+// ScaleFloat multiplies by f, rounding half away from zero. f is taken at
+// its shortest decimal representation so 0.1 means exactly one tenth.
+func (m Money) ScaleFloat(f float64) Money {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return New(roundRat(r.Mul(r, new(big.Rat).SetInt64(m.amount))), m.currency)
+}
+
+// Convert turns m into the target currency at rate units of to per unit of
+// m's currency, rounding half away from zero to the target minor unit.
+func (m Money) Convert(to Currency, rate float64) (Money, error) {
+	fromExp, err := m.currency.MinorUnits()
+	if err != nil {
+		return Money{}, err
+	}
+	toExp, err := to.MinorUnits()
+	if err != nil {
+		return Money{}, err
+	}
+	r, ok := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+	if !ok || r.Sign() <= 0 {
+		return Money{}, fmt.Errorf("%w: exchange rate %v", ErrInvalidAmount, rate)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(m.amount))
+	shift := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil)
+	if toExp >= fromExp {
+		r.Mul(r, new(big.Rat).SetInt(shift))
+	} else {
+		r.Quo(r, new(big.Rat).SetInt(shift))
+	}
+	return New(roundRat(r), to), nil
+}
+
+// Allocate splits m across the given ratios without losing minor units; any
+// remainder goes one unit at a time to the leading shares.
+func (m Money) Allocate(ratios ...int64) []Money {
+	shares := make([]Money, len(ratios))
+	var total int64
+	for _, r := range ratios {
+		total += r
+	}
+	if total == 0 {
+		for i := range shares {
+			shares[i] = Zero(m.currency)
+		}
+		return shares
+	}
+	remainder := m.amount
+	for i, r := range ratios {
+		share := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(r))
+		share.Quo(share, big.NewInt(total))
+		shares[i] = New(share.Int64(), m.currency)
+		remainder -= share.Int64()
+	}
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(shares) {
+		if ratios[i] == 0 {
+			continue
+		}
+		shares[i].amount += step
+		remainder -= step
+	}
+	return shares
+}
+
+func (m Money) String() string {
+	exp := minorUnits[m.currency]
+	abs := m.amount
+	sign := ""
+	if abs < 0 {
+		abs = -abs
+		sign = "-"
+	}
+	digits := strconv.FormatInt(abs, 10)
+	if exp > 0 {
+		if len(digits) <= exp {
+			digits = strings.Repeat("0", exp-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+	}
+	return fmt.Sprintf("%s %s%s", m.currency, sign, digits)
+}
+
+func (m Money) assertSameCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func roundRat(r *big.Rat) int64 {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() != 0 && new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2)).Cmp(r.Denom()) >= 0 {
+		quo.Add(quo, big.NewInt(int64(r.Num().Sign())))
+	}
+	return quo.Int64()
+}
@@ -0,0 +1,295 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	shoppingcart "strategy-design/shopping-cart"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCartChanged = errors.New("cart no longer matches the order")
+	ErrNotPaid     = errors.New("order has no settled payment")
+)
+
+// Order is a placed cart. It snapshots the cart's lines and quote, then
+// drives payment, fulfilment and refunds through the cart while keeping
+// its state and every transition.
+type Order struct {
+	mu      sync.Mutex
+	id      string
+	cart    *shoppingcart.ShoppingCart
+	items   []shoppingcart.LineItem
+	quote   *shoppingcart.Quote
+	state   State
+	history []Transition
+	now     func() time.Time
+
+	payment *paymentstrategy.Receipt
+	auth    *paymentstrategy.Authorization
+	// cancelling is set while Cancel withdraws a pending payment, so the
+	// resulting settlement does not move the order back to Created.
+	cancelling bool
+}
+
+func New(cart *shoppingcart.ShoppingCart) (*Order, error) {
+	if len(cart.Items()) == 0 {
+		return nil, shoppingcart.ErrEmptyCart
+	}
+	quote, err := cart.Quote()
+	if err != nil {
+		return nil, err
+	}
+	o := &Order{
+		id:    "ord_" + strings.TrimPrefix(paymentstrategy.NewTransactionID(), "txn_"),
+		cart:  cart,
+		items: cart.Items(),
+		quote: quote,
+		state: Created,
+		now:   time.Now,
+	}
+	o.history = append(o.history, Transition{To: Created, At: o.now(), Note: "order placed for " + quote.Total.String()})
+	return o, nil
+}
+
+func (o *Order) SetClock(now func() time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.now = now
+}
+
+func (o *Order) ID() string {
+	return o.id
+}
+
+func (o *Order) Items() []shoppingcart.LineItem {
+	return append([]shoppingcart.LineItem(nil), o.items...)
+}
+
+func (o *Order) Total() money.Money {
+	return o.quote.Total
+}
+
+func (o *Order) State() State {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.state
+}
+
+func (o *Order) History() []Transition {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Transition(nil), o.history...)
+}
+
+// Payment returns the receipt for the order's charge, if any.
+func (o *Order) Payment() *paymentstrategy.Receipt {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.payment
+}
+
+// Pay charges the order through the cart. A charge that settles later,
+// such as an on-chain payment, leaves the order pending until it resolves;
+// a failed charge returns it to Created so it can be paid again.
+func (o *Order) Pay(ctx context.Context) (*paymentstrategy.Receipt, error) {
+	receipt, err := o.pay(ctx)
+	if receipt.Pending() {
+		receipt.OnSettle(o.settled)
+	}
+	return receipt, err
+}
+
+func (o *Order) pay(ctx context.Context) (*paymentstrategy.Receipt, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.begin(); err != nil {
+		return nil, err
+	}
+	receipt, err := o.cart.Checkout(ctx)
+	if err != nil && !errors.Is(err, shoppingcart.ErrLedger) {
+		o.move(Created, "payment failed: "+err.Error())
+		return receipt, err
+	}
+	o.payment = receipt
+	o.settle()
+	return receipt, err
+}
+
+// Authorize holds the order total now so it can be captured when the
+// order ships.
+func (o *Order) Authorize(ctx context.Context) (*paymentstrategy.Authorization, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.begin(); err != nil {
+		return nil, err
+	}
+	auth, err := o.cart.AuthorizeCheckout(ctx)
+	if err != nil {
+		o.move(Created, "authorization failed: "+err.Error())
+		return nil, err
+	}
+	o.auth = auth
+	o.move(PaymentPending, "authorized "+auth.ID)
+	return auth, nil
+}
+
+// settled moves a pending order on once its charge resolves.
+func (o *Order) settled(final *paymentstrategy.Receipt, _ error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.state != PaymentPending || o.payment == nil || o.payment.TransactionID != final.TransactionID {
+		return
+	}
+	o.payment = final
+	if o.cancelling {
+		return
+	}
+	o.settle()
+}
+
+// Fulfil marks the order shipped, capturing the authorization first if it
+// was paid that way.
+func (o *Order) Fulfil(ctx context.Context) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.auth != nil && o.state == PaymentPending {
+		receipt, err := o.cart.CaptureShipmentFull(ctx, o.auth.ID)
+		if err != nil && !errors.Is(err, shoppingcart.ErrLedger) {
+			return err
+		}
+		o.payment = receipt
+		if err := o.move(Paid, "captured "+o.auth.ID+" as "+receipt.TransactionID); err != nil {
+			return err
+		}
+	}
+	return o.move(Fulfilled, "shipped")
+}
+
+// Cancel abandons the order. A payment still waiting to settle is
+// withdrawn, an open authorization is voided and a settled payment that
+// has not shipped is refunded in full.
+func (o *Order) Cancel(ctx context.Context, reason string) error {
+	// Withdrawing a pending charge resolves it, which calls back into the
+	// order, so it happens before taking the lock.
+	pending := o.withdrawing()
+	var withdrawErr error
+	if pending != nil {
+		withdrawErr = pending.CancelPending()
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if pending != nil {
+		o.cancelling = false
+		if withdrawErr != nil {
+			// The charge could not be withdrawn; if it has settled in
+			// the meantime, apply the outcome that was held back.
+			if o.payment != pending {
+				o.settle()
+			}
+			return withdrawErr
+		}
+	}
+	if !o.state.CanMoveTo(Cancelled) {
+		return o.illegal(Cancelled)
+	}
+	switch {
+	case o.state == PaymentPending && o.auth != nil:
+		if err := o.cart.VoidAuthorization(ctx, o.auth.ID); err != nil {
+			return err
+		}
+	case o.state == Paid:
+		if _, err := o.cart.RefundFull(ctx, o.payment.TransactionID); err != nil && !errors.Is(err, shoppingcart.ErrLedger) {
+			return err
+		}
+	}
+	return o.move(Cancelled, reason)
+}
+
+// Refund returns amount of the order's payment, leaving the order
+// partially refunded until nothing remains.
+func (o *Order) Refund(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.payment == nil || !o.payment.Succeeded() {
+		return nil, ErrNotPaid
+	}
+	if !o.state.CanMoveTo(PartiallyRefunded) && !o.state.CanMoveTo(Refunded) {
+		return nil, o.illegal(PartiallyRefunded)
+	}
+	refund, err := o.cart.Refund(ctx, o.payment.TransactionID, amount)
+	if err != nil && (refund == nil || !refund.Succeeded()) {
+		return refund, err
+	}
+	remaining, _ := o.cart.Refundable(o.payment.TransactionID)
+	to := PartiallyRefunded
+	if remaining.IsZero() {
+		to = Refunded
+	}
+	note := fmt.Sprintf("refunded %s, %s remaining", amount, remaining)
+	if moveErr := o.move(to, note); moveErr != nil {
+		return refund, moveErr
+	}
+	return refund, err
+}
+
+// withdrawing returns the payment still waiting to settle, if any, and
+// marks the order as cancelling it.
+func (o *Order) withdrawing() *paymentstrategy.Receipt {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.state == PaymentPending && o.payment.Pending() {
+		o.cancelling = true
+		return o.payment
+	}
+	return nil
+}
+
+// begin moves a created order to PaymentPending, checking the cart still
+// holds the lines and total the order was placed with.
+func (o *Order) begin() error {
+	if o.state != Created {
+		return o.illegal(PaymentPending)
+	}
+	if !slices.Equal(o.cart.Items(), o.items) {
+		return fmt.Errorf("%w: order %s lines differ from the cart", ErrCartChanged, o.id)
+	}
+	quote, err := o.cart.Quote()
+	if err != nil {
+		return err
+	}
+	if !quote.Total.Equal(o.quote.Total) {
+		return fmt.Errorf("%w: order %s is for %s, cart now totals %s", ErrCartChanged, o.id, o.quote.Total, quote.Total)
+	}
+	return o.move(PaymentPending, "payment started")
+}
+
+func (o *Order) settle() {
+	switch {
+	case o.payment.Succeeded():
+		o.move(Paid, "paid by "+o.payment.Method+" as "+o.payment.TransactionID)
+	case o.payment.Pending():
+		o.move(PaymentPending, "awaiting settlement of "+o.payment.TransactionID)
+	default:
+		o.move(Created, fmt.Sprintf("payment %s %s", o.payment.TransactionID, o.payment.Status))
+	}
+}
+
+func (o *Order) move(to State, note string) error {
+	if !o.state.CanMoveTo(to) {
+		return o.illegal(to)
+	}
+	o.history = append(o.history, Transition{From: o.state, To: to, At: o.now(), Note: note})
+	o.state = to
+	return nil
+}
+
+func (o *Order) illegal(to State) error {
+	return fmt.Errorf("%w: order %s cannot go from %s to %s", ErrIllegalTransition, o.id, o.state, to)
+}
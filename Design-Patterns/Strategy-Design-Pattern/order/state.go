@@ -0,0 +1,57 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrIllegalTransition = errors.New("illegal order transition")
+
+type State string
+
+const (
+	Created           State = "created"
+	PaymentPending    State = "payment pending"
+	Paid              State = "paid"
+	Fulfilled         State = "fulfilled"
+	Cancelled         State = "cancelled"
+	Refunded          State = "refunded"
+	PartiallyRefunded State = "partially refunded"
+)
+
+// transitions lists, for each state, the states an order may move to.
+// Cancelled and Refunded are terminal. A pending payment may record
+// progress, such as an authorization, as a move to the same state.
+var transitions = map[State][]State{
+	Created:           {PaymentPending, Cancelled},
+	PaymentPending:    {PaymentPending, Paid, Created, Cancelled},
+	Paid:              {Fulfilled, PartiallyRefunded, Refunded, Cancelled},
+	Fulfilled:         {PartiallyRefunded, Refunded},
+	PartiallyRefunded: {PartiallyRefunded, Refunded},
+}
+
+func (s State) CanMoveTo(to State) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s State) Terminal() bool {
+	return len(transitions[s]) == 0
+}
+
+// Transition is one entry in an order's history.
+type Transition struct {
+	From State
+	To   State
+	At   time.Time
+	Note string
+}
+
+func (t Transition) String() string {
+	return fmt.Sprintf("%s %s -> %s: %s", t.At.Format(time.RFC3339), t.From, t.To, t.Note)
+}
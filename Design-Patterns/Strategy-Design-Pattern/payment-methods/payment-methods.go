@@ -0,0 +1,11 @@
+// Package paymentmethods links in every built-in payment strategy so that
+// importing it is enough to make them available to paymentstrategy.New.
+package paymentmethods
+
+import (
+	_ "strategy-design/bitcoin"
+	_ "strategy-design/credit-card"
+	_ "strategy-design/gift-card"
+	_ "strategy-design/paypal"
+	_ "strategy-design/wallet"
+)
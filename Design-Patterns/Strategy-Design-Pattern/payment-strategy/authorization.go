@@ -0,0 +1,203 @@
+package paymentstrategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAuthorizationTTL is how long an authorization can be captured,
+// matching the usual hold period on card networks.
+const DefaultAuthorizationTTL = 7 * 24 * time.Hour
+
+var (
+	ErrAuthorizeUnsupported = errors.New("payment method does not support authorization")
+	ErrUnknownAuthorization = errors.New("authorization not found for this payment method")
+	ErrAuthorizationClosed  = errors.New("authorization already captured or voided")
+	ErrAuthorizationExpired = errors.New("authorization has expired")
+)
+
+// Authorizer is implemented by strategies that can hold funds now and take
+// them later. Capture may take less than was authorized, in which case the
+// rest is released; an authorization is captured at most once.
+type Authorizer interface {
+	Authorize(ctx context.Context, amount money.Money) (*Authorization, error)
+	Capture(ctx context.Context, auth *Authorization, amount money.Money) (*Receipt, error)
+	Void(ctx context.Context, auth *Authorization) error
+}
+
+type AuthorizationStatus string
+
+const (
+	AuthorizationOpen     AuthorizationStatus = "authorized"
+	AuthorizationCaptured AuthorizationStatus = "captured"
+	AuthorizationVoided   AuthorizationStatus = "voided"
+	AuthorizationExpired  AuthorizationStatus = "expired"
+)
+
+type Authorization struct {
+	ID         string
+	Method     string
+	Instrument string
+	Amount     money.Money
+	// Captured is what was eventually taken, zero until captured.
+	Captured  money.Money
+	Status    AuthorizationStatus
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// Reference is the strategy's own handle for the hold, if it has one.
+	Reference string
+}
+
+// AuthorizationFailed wraps an error from Authorize or Void the same way
+// Receipt.Fail wraps one from Pay.
+func AuthorizationFailed(method string, err error) error {
+	return &PaymentError{Method: method, TransactionID: "authorization", Err: err}
+}
+
+func (a *Authorization) String() string {
+	return fmt.Sprintf("[%s] %s %s via %s, expires %s", a.Status, a.ID, a.Amount, a.Method, a.ExpiresAt.Format(time.RFC3339))
+}
+
+// AuthorizationBook tracks a strategy's open authorizations and enforces
+// expiry and single capture, the way RefundBook enforces refund limits.
+type AuthorizationBook struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	now   func() time.Time
+	auths map[string]*Authorization
+}
+
+func NewAuthorizationBook(ttl time.Duration) *AuthorizationBook {
+	if ttl <= 0 {
+		ttl = DefaultAuthorizationTTL
+	}
+	return &AuthorizationBook{
+		ttl:   ttl,
+		now:   time.Now,
+		auths: make(map[string]*Authorization),
+	}
+}
+
+func (b *AuthorizationBook) SetClock(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.now = now
+}
+
+// Open records a new authorization and returns a copy of it.
+func (b *AuthorizationBook) Open(method, instrument string, amount money.Money, reference string) *Authorization {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	auth := &Authorization{
+		ID:         "auth_" + strings.TrimPrefix(NewTransactionID(), "txn_"),
+		Method:     method,
+		Instrument: instrument,
+		Amount:     amount,
+		Captured:   money.Zero(amount.Currency()),
+		Status:     AuthorizationOpen,
+		CreatedAt:  now,
+		ExpiresAt:  now.Add(b.ttl),
+		Reference:  reference,
+	}
+	b.auths[auth.ID] = auth
+	copied := *auth
+	return &copied
+}
+
+// Capture marks the authorization captured for amount. Call Reopen if the
+// capture then fails downstream.
+func (b *AuthorizationBook) Capture(id string, amount money.Money) (*Authorization, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	auth, err := b.open(id)
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidAmount
+	}
+	if cmp, err := amount.Cmp(auth.Amount); err != nil {
+		return nil, err
+	} else if cmp > 0 {
+		return nil, fmt.Errorf("%w: capture %s exceeds authorized %s on %s", ErrInvalidAmount, amount, auth.Amount, id)
+	}
+	auth.Captured = amount
+	auth.Status = AuthorizationCaptured
+	copied := *auth
+	return &copied, nil
+}
+
+func (b *AuthorizationBook) Reopen(id string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if auth, ok := b.auths[id]; ok && auth.Status == AuthorizationCaptured {
+		auth.Captured = money.Zero(auth.Amount.Currency())
+		auth.Status = AuthorizationOpen
+	}
+}
+
+// SetReference records the strategy's own handle for an authorization, for
+// strategies that only get one after Open, such as a hold placed for it.
+func (b *AuthorizationBook) SetReference(id, reference string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	auth, ok := b.auths[id]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownAuthorization, id)
+	}
+	auth.Reference = reference
+	return nil
+}
+
+// Void closes an open authorization without capturing it.
+func (b *AuthorizationBook) Void(id string) (*Authorization, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	auth, err := b.open(id)
+	if err != nil {
+		return nil, err
+	}
+	auth.Status = AuthorizationVoided
+	copied := *auth
+	return &copied, nil
+}
+
+func (b *AuthorizationBook) Get(id string) (*Authorization, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	auth, ok := b.auths[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAuthorization, id)
+	}
+	b.expire(auth)
+	copied := *auth
+	return &copied, nil
+}
+
+// open returns the authorization if it can still be captured or voided.
+func (b *AuthorizationBook) open(id string) (*Authorization, error) {
+	auth, ok := b.auths[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAuthorization, id)
+	}
+	b.expire(auth)
+	switch auth.Status {
+	case AuthorizationOpen:
+		return auth, nil
+	case AuthorizationExpired:
+		return nil, fmt.Errorf("%w: %s expired at %s", ErrAuthorizationExpired, id, auth.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil, fmt.Errorf("%w: %s is %s", ErrAuthorizationClosed, id, auth.Status)
+}
+
+func (b *AuthorizationBook) expire(auth *Authorization) {
+	if auth.Status == AuthorizationOpen && !b.now().Before(auth.ExpiresAt) {
+		auth.Status = AuthorizationExpired
+	}
+}
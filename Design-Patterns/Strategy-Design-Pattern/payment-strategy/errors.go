@@ -0,0 +1,67 @@
+package paymentstrategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrDeclined          = errors.New("payment declined")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrInvalidInstrument = errors.New("invalid payment instrument")
+	ErrInvalidAmount     = errors.New("invalid payment amount")
+	ErrRefundUnsupported = errors.New("payment method does not support refunds")
+	ErrUnknownCharge     = errors.New("charge not found for this payment method")
+	ErrOverRefund        = errors.New("refund exceeds the amount still refundable")
+
+	ErrCancelled = errors.New("payment cancelled")
+
+	// Transient failures: the same request may succeed if retried.
+	ErrGatewayUnavailable = errors.New("payment gateway unavailable")
+	ErrTimeout            = errors.New("payment timed out")
+)
+
+type PaymentError struct {
+	Method        string
+	TransactionID string
+	Err           error
+}
+
+func (e *PaymentError) Error() string {
+	return fmt.Sprintf("%s payment %s: %v", e.Method, e.TransactionID, e.Err)
+}
+
+func (e *PaymentError) Unwrap() error {
+	return e.Err
+}
+
+// Cancelled returns nil while ctx is live and an error wrapping both
+// ErrCancelled and the context's error once it is done.
+func Cancelled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
+	return nil
+}
+
+func IsCancelled(err error) bool {
+	return errors.Is(err, ErrCancelled) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+func IsTransient(err error) bool {
+	if IsCancelled(err) {
+		return false
+	}
+	return errors.Is(err, ErrGatewayUnavailable) || errors.Is(err, ErrTimeout)
+}
+
+func statusFor(err error) Status {
+	if IsCancelled(err) {
+		return StatusCancelled
+	}
+	if errors.Is(err, ErrDeclined) || errors.Is(err, ErrInsufficientFunds) {
+		return StatusDeclined
+	}
+	return StatusFailed
+}
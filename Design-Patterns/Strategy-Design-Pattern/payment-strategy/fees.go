@@ -0,0 +1,181 @@
+package paymentstrategy
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	"strings"
+)
+
+var ErrNotEligible = errors.New("payment method cannot take this amount")
+
+// FeeRule is a method's pricing in one currency: a percentage in basis
+// points plus a fixed amount, optionally bounded below and above, and the
+// range of charge amounts the method accepts. Zero bounds are unset.
+type FeeRule struct {
+	Currency   money.Currency
+	PercentBPS int64
+	Fixed      money.Money
+	MinFee     money.Money
+	MaxFee     money.Money
+	MinAmount  money.Money
+	MaxAmount  money.Money
+}
+
+// FeeModel holds a method's rules, one per currency it accepts.
+type FeeModel []FeeRule
+
+// FeeBreakdown shows how a fee was worked out.
+type FeeBreakdown struct {
+	Amount     money.Money
+	Percentage money.Money
+	Fixed      money.Money
+	// Adjustment is what the minimum or cap added to or took off the sum
+	// of Percentage and Fixed.
+	Adjustment money.Money
+	Total      money.Money
+}
+
+func (b FeeBreakdown) String() string {
+	s := fmt.Sprintf("%s (%s + %s fixed", b.Total, b.Percentage, b.Fixed)
+	if !b.Adjustment.IsZero() {
+		s += fmt.Sprintf(", adjusted by %s for the minimum or cap", b.Adjustment)
+	}
+	return s + ")"
+}
+
+// FeeQuoter is implemented by strategies that declare their fee model.
+type FeeQuoter interface {
+	Fees() FeeModel
+}
+
+// AvailabilityChecker is implemented by strategies that can tell up front
+// whether a charge would go through, such as stored-value methods that
+// know their balance.
+type AvailabilityChecker interface {
+	CanPay(amount money.Money) error
+}
+
+// Quote prices a charge of amount, failing with ErrNotEligible when the
+// model has no rule for its currency or the amount is outside the limits.
+func (m FeeModel) Quote(amount money.Money) (FeeBreakdown, error) {
+	for _, rule := range m {
+		if rule.Currency == amount.Currency() {
+			return rule.quote(amount)
+		}
+	}
+	return FeeBreakdown{}, fmt.Errorf("%w: %s not accepted", ErrNotEligible, amount.Currency())
+}
+
+// Fee is Quote without the breakdown, and zero when the charge is not
+// covered by the model.
+func (m FeeModel) Fee(amount money.Money) money.Money {
+	b, err := m.Quote(amount)
+	if err != nil {
+		return money.Zero(amount.Currency())
+	}
+	return b.Total
+}
+
+func (r FeeRule) quote(amount money.Money) (FeeBreakdown, error) {
+	if less(amount, r.MinAmount) {
+		return FeeBreakdown{}, fmt.Errorf("%w: %s is below the minimum of %s", ErrNotEligible, amount, r.MinAmount)
+	}
+	if !r.MaxAmount.IsZero() && less(r.MaxAmount, amount) {
+		return FeeBreakdown{}, fmt.Errorf("%w: %s is above the limit of %s", ErrNotEligible, amount, r.MaxAmount)
+	}
+	zero := money.Zero(amount.Currency())
+	b := FeeBreakdown{Amount: amount, Percentage: amount.Scale(r.PercentBPS, 10000), Fixed: zero, Adjustment: zero}
+	if !r.Fixed.IsZero() {
+		b.Fixed = r.Fixed
+	}
+	total, err := b.Percentage.Add(b.Fixed)
+	if err != nil {
+		return FeeBreakdown{}, err
+	}
+	bounded := total
+	if less(bounded, r.MinFee) {
+		bounded = r.MinFee
+	}
+	if !r.MaxFee.IsZero() && less(r.MaxFee, bounded) {
+		bounded = r.MaxFee
+	}
+	b.Adjustment, _ = bounded.Sub(total)
+	b.Total = bounded
+	return b, nil
+}
+
+// less reports a < b, treating an unset b as no bound.
+func less(a, b money.Money) bool {
+	if b.IsZero() || !a.SameCurrency(b) {
+		return false
+	}
+	cmp, _ := a.Cmp(b)
+	return cmp < 0
+}
+
+// Candidate is one strategy considered by SelectCheapest.
+type Candidate struct {
+	Strategy PaymentStrategy
+	Fee      FeeBreakdown
+	// Err explains why the strategy was not eligible.
+	Err error
+}
+
+type Selection struct {
+	Chosen     Candidate
+	Candidates []Candidate
+}
+
+func (s *Selection) String() string {
+	lines := make([]string, 0, len(s.Candidates))
+	for _, c := range s.Candidates {
+		name := fmt.Sprintf("%T", c.Strategy)
+		if id, ok := c.Strategy.(Identifier); ok {
+			name = id.Identify().Method
+		}
+		if c.Err != nil {
+			lines = append(lines, fmt.Sprintf("%s: %v", name, c.Err))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s: fee %s", name, c.Fee))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SelectCheapest picks the eligible strategy with the lowest fee for
+// amount, keeping the earliest on a tie. Strategies that do not declare a
+// fee model, or declare a nil one, are not eligible, since their cost is
+// unknown.
+func SelectCheapest(amount money.Money, strategies ...PaymentStrategy) (*Selection, error) {
+	selection := &Selection{}
+	best := -1
+	for _, strategy := range strategies {
+		c := Candidate{Strategy: strategy}
+		var model FeeModel
+		if quoter, ok := strategy.(FeeQuoter); ok {
+			model = quoter.Fees()
+		}
+		if model == nil {
+			c.Err = fmt.Errorf("%w: no fee model declared", ErrNotEligible)
+		} else if c.Fee, c.Err = model.Quote(amount); c.Err == nil {
+			if checker, ok := strategy.(AvailabilityChecker); ok {
+				c.Err = checker.CanPay(amount)
+			}
+		}
+		selection.Candidates = append(selection.Candidates, c)
+		if c.Err != nil {
+			continue
+		}
+		if best < 0 {
+			best = len(selection.Candidates) - 1
+		} else if cmp, _ := c.Fee.Total.Cmp(selection.Candidates[best].Fee.Total); cmp < 0 {
+			best = len(selection.Candidates) - 1
+		}
+	}
+	if best < 0 {
+		return selection, fmt.Errorf("%w: none of %d method(s) can take %s", ErrNotEligible, len(strategies), amount)
+	}
+	selection.Chosen = selection.Candidates[best]
+	return selection, nil
+}
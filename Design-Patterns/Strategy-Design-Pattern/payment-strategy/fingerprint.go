@@ -0,0 +1,47 @@
+package paymentstrategy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Instruments are fingerprinted with a keyed hash: card numbers and gift
+// card codes come from spaces small enough that a plain hash could be
+// reversed by trying every value.
+var (
+	fingerprintMu  sync.RWMutex
+	fingerprintKey = randomKey()
+)
+
+func randomKey() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}
+
+// SetFingerprintKey replaces the per-process random key, so fingerprints
+// stay the same across restarts and between services sharing the key.
+// Fingerprints taken under the old key, such as blocklist entries and
+// velocity history, no longer match anything afterwards; set the key
+// before they are recorded, or record them again.
+func SetFingerprintKey(key []byte) {
+	fingerprintMu.Lock()
+	defer fingerprintMu.Unlock()
+	fingerprintKey = append([]byte(nil), key...)
+}
+
+// Fingerprint returns a stable token for value that reveals nothing of it,
+// prefixed with kind so different instruments never share a token. value
+// must already be normalised by the caller.
+func Fingerprint(kind, value string) string {
+	fingerprintMu.RLock()
+	mac := hmac.New(sha256.New, fingerprintKey)
+	fingerprintMu.RUnlock()
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return kind + "_" + hex.EncodeToString(mac.Sum(nil)[:12])
+}
@@ -1,5 +1,40 @@
 package paymentstrategy
 
-type PaymentStrategy interface{
-	Pay(amount float64)
-}
\ No newline at end of file
+import (
+	"context"
+	"strategy-design/money"
+)
+
+// PaymentStrategy charges an amount and reports the outcome. On failure the
+// returned receipt (if any) carries the declined/failed/cancelled status and
+// the error is a *PaymentError wrapping one of the Err* values. Pay must
+// stop and report StatusCancelled once ctx is done.
+type PaymentStrategy interface {
+	Pay(ctx context.Context, amount money.Money) (*Receipt, error)
+}
+
+// Refunder is implemented by strategies that can return money from a
+// receipt they previously issued.
+type Refunder interface {
+	Refund(ctx context.Context, original *Receipt, amount money.Money) (*Receipt, error)
+}
+
+// Identifier is implemented by strategies that can describe the instrument
+// they will charge before charging it, so the charge can be screened.
+type Identifier interface {
+	Identify() Instrument
+}
+
+type Instrument struct {
+	Method string
+	// ID is a stable key for matching the same instrument across charges:
+	// a keyed fingerprint of a card number, a PayPal email, a wallet
+	// address. It is not meant for display; use Display.
+	ID string
+	// Display names the instrument for people, showing at most the last
+	// four digits of a card.
+	Display string
+	Email   string
+	// Country is the ISO 3166 code of the issuing country, if known.
+	Country string
+}
@@ -0,0 +1,115 @@
+package paymentstrategy
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	// ErrDeferredSettlement is returned where a charge has to be final at
+	// once but the method only settles later.
+	ErrDeferredSettlement = errors.New("payment method settles later and cannot be used here")
+	ErrAlreadySettled     = errors.New("pending payment has already settled")
+)
+
+// PendingCharge follows a charge that was accepted but settles later. The
+// strategy that made it resolves it exactly once with Settle or Fail;
+// anyone holding the pending receipt learns the outcome through
+// Receipt.OnSettle. The pending receipt itself is never changed: the
+// outcome is a copy, so it can be shared freely while it waits.
+type PendingCharge struct {
+	mu      sync.Mutex
+	base    Receipt
+	cancel  func() error
+	final   *Receipt
+	err     error
+	waiters []func(*Receipt, error)
+}
+
+// Defer marks the receipt pending and returns the handle its strategy uses
+// to resolve it. cancel, if not nil, is called by CancelPending to stop
+// the strategy accepting money for the charge. Call Defer last, once the
+// receipt is otherwise complete.
+func (r *Receipt) Defer(cancel func() error) *PendingCharge {
+	r.Status = StatusPending
+	p := &PendingCharge{base: *r, cancel: cancel}
+	r.pending = p
+	return p
+}
+
+// OnSettle calls fn with the final receipt once a pending charge resolves,
+// on the goroutine that resolves it. For a receipt that is not pending,
+// or has already resolved, fn runs before OnSettle returns.
+func (r *Receipt) OnSettle(fn func(final *Receipt, err error)) {
+	p := r.pending
+	if p == nil {
+		fn(r, nil)
+		return
+	}
+	p.mu.Lock()
+	if p.final == nil {
+		p.waiters = append(p.waiters, fn)
+		p.mu.Unlock()
+		return
+	}
+	final, err := p.final, p.err
+	p.mu.Unlock()
+	fn(final, err)
+}
+
+// CancelPending withdraws a charge that has not settled yet. It fails with
+// ErrAlreadySettled if the charge resolved first, or with the strategy's
+// error if it can no longer stop the payment.
+func (r *Receipt) CancelPending() error {
+	p := r.pending
+	if p == nil {
+		return fmt.Errorf("%w: %s is %s", ErrAlreadySettled, r.TransactionID, r.Status)
+	}
+	if p.cancel != nil {
+		if err := p.cancel(); err != nil {
+			return err
+		}
+	}
+	_, err := p.Fail(ErrCancelled)
+	if errors.Is(err, ErrAlreadySettled) {
+		return err
+	}
+	return nil
+}
+
+// Settle resolves the charge as succeeded and returns the final receipt.
+func (p *PendingCharge) Settle() (*Receipt, error) {
+	return p.resolve(nil)
+}
+
+// Fail resolves the charge as declined or failed depending on err, like
+// Receipt.Fail.
+func (p *PendingCharge) Fail(err error) (*Receipt, error) {
+	return p.resolve(err)
+}
+
+func (p *PendingCharge) resolve(cause error) (*Receipt, error) {
+	p.mu.Lock()
+	if p.final != nil {
+		final := p.final
+		p.mu.Unlock()
+		return final, fmt.Errorf("%w: %s is %s", ErrAlreadySettled, final.TransactionID, final.Status)
+	}
+	final := p.base
+	final.pending = nil
+	final.Status = StatusSucceeded
+	var err error
+	if cause != nil {
+		_, err = final.Fail(cause)
+	}
+	p.final, p.err = &final, err
+	waiters := p.waiters
+	p.waiters = nil
+	p.mu.Unlock()
+
+	for _, fn := range waiters {
+		fn(&final, err)
+	}
+	return &final, err
+}
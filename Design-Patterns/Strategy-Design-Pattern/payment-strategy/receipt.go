@@ -0,0 +1,162 @@
+package paymentstrategy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strategy-design/money"
+	"strconv"
+	"time"
+)
+
+type Status string
+
+const (
+	StatusSucceeded Status = "succeeded"
+	StatusDeclined  Status = "declined"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+	// StatusPending marks a charge that was accepted but settles later, such
+	// as an on-chain payment waiting for confirmations. It is not paid
+	// yet; see Receipt.OnSettle.
+	StatusPending Status = "pending"
+)
+
+type Receipt struct {
+	TransactionID string
+	// OriginalTransactionID is set on refund receipts and points at the
+	// charge being reversed.
+	OriginalTransactionID string
+	Method                string
+	Instrument            string
+	Amount                money.Money
+	SettledAmount         money.Money
+	ExchangeRate          float64
+	// Fee is the processing fee the payment method keeps, if known.
+	Fee       money.Money
+	Timestamp time.Time
+	Status    Status
+	// Subtotal, Discounts and Taxes itemise how the cart arrived at Amount.
+	Subtotal  money.Money
+	Discounts []Adjustment
+	Taxes     []Adjustment
+	// Attempts lists every try made to produce this receipt when the charge
+	// went through a retrying or fallback strategy.
+	Attempts []Attempt
+
+	pending *PendingCharge
+}
+
+// Adjustment is an itemised change to a cart total, such as a coupon or a
+// tax. SKU is set when it applies to one line item only; Inclusive marks a
+// tax that is already contained in the price and was not added on top.
+type Adjustment struct {
+	Code        string
+	Description string
+	SKU         string
+	Amount      money.Money
+	Inclusive   bool
+}
+
+type Attempt struct {
+	Method        string
+	TransactionID string
+	Number        int
+	Status        Status
+	Error         string
+	Timestamp     time.Time
+}
+
+func NewReceipt(method string, amount money.Money) *Receipt {
+	return &Receipt{
+		TransactionID: NewTransactionID(),
+		Method:        method,
+		Amount:        amount,
+		SettledAmount: amount,
+		ExchangeRate:  1,
+		Timestamp:     time.Now(),
+		Status:        StatusSucceeded,
+	}
+}
+
+// Fail marks the receipt as declined or failed depending on err and returns
+// it together with the matching *PaymentError.
+func (r *Receipt) Fail(err error) (*Receipt, error) {
+	r.Status = statusFor(err)
+	return r, &PaymentError{Method: r.Method, TransactionID: r.TransactionID, Err: err}
+}
+
+// Settle records the amount actually moved in the strategy's own currency
+// and the rate applied to get there.
+func (r *Receipt) Settle(settled money.Money, rate float64) {
+	r.SettledAmount = settled
+	r.ExchangeRate = rate
+}
+
+// NewRefundReceipt starts a refund of amount against original. The settled
+// amount is scaled from the original so converted charges refund at the
+// rate they were taken at.
+func NewRefundReceipt(original *Receipt, amount money.Money) *Receipt {
+	r := NewReceipt(original.Method, amount)
+	r.OriginalTransactionID = original.TransactionID
+	r.Instrument = original.Instrument
+	if original.SettledAmount.Currency() != original.Amount.Currency() && !original.Amount.IsZero() {
+		r.Settle(original.SettledAmount.Scale(amount.Amount(), original.Amount.Amount()), original.ExchangeRate)
+	}
+	return r
+}
+
+func (r *Receipt) Succeeded() bool {
+	return r != nil && r.Status == StatusSucceeded
+}
+
+func (r *Receipt) Pending() bool {
+	return r != nil && r.Status == StatusPending
+}
+
+func (r *Receipt) IsRefund() bool {
+	return r.OriginalTransactionID != ""
+}
+
+func (r *Receipt) String() string {
+	amount := r.Amount.String()
+	if r.SettledAmount.Currency() != r.Amount.Currency() {
+		amount = fmt.Sprintf("%s (settled %s @ %s)", r.Amount, r.SettledAmount, strconv.FormatFloat(r.ExchangeRate, 'f', -1, 64))
+	}
+	for _, d := range r.Discounts {
+		amount += fmt.Sprintf(" [%s -%s]", d.Code, d.Amount)
+	}
+	if tax, ok := totalTax(r.Taxes); ok {
+		amount += fmt.Sprintf(" [tax %s]", tax)
+	}
+	if !r.Fee.IsZero() {
+		amount += fmt.Sprintf(" [fee %s]", r.Fee)
+	}
+	method := r.Method
+	if r.Instrument != "" {
+		method = fmt.Sprintf("%s (%s)", r.Method, r.Instrument)
+	}
+	if r.IsRefund() {
+		return fmt.Sprintf("[%s] %s refund of %s: %s via %s at %s", r.Status, r.TransactionID, r.OriginalTransactionID, amount, method, r.Timestamp.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("[%s] %s %s via %s at %s", r.Status, r.TransactionID, amount, method, r.Timestamp.Format(time.RFC3339))
+}
+
+func totalTax(taxes []Adjustment) (money.Money, bool) {
+	if len(taxes) == 0 {
+		return money.Money{}, false
+	}
+	var minor int64
+	for _, t := range taxes {
+		minor += t.Amount.Amount()
+	}
+	return money.New(minor, taxes[0].Amount.Currency()), true
+}
+
+func NewTransactionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("txn_%d", time.Now().UnixNano())
+	}
+	return "txn_" + hex.EncodeToString(b)
+}
@@ -0,0 +1,21 @@
+package paymentstrategy
+
+import "context"
+
+type referenceKey struct{}
+
+// WithReference returns a context carrying an idempotency reference for the
+// charge being made. Strategies that call a gateway send it in place of a
+// fresh transaction ID, so a retried request is recognised as the same
+// charge instead of a second one.
+func WithReference(ctx context.Context, reference string) context.Context {
+	return context.WithValue(ctx, referenceKey{}, reference)
+}
+
+// Reference returns the reference carried by ctx, or fallback if none was set.
+func Reference(ctx context.Context, fallback string) string {
+	if reference, ok := ctx.Value(referenceKey{}).(string); ok && reference != "" {
+		return reference
+	}
+	return fallback
+}
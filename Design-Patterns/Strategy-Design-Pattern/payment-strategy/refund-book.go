@@ -0,0 +1,109 @@
+package paymentstrategy
+
+import (
+	"fmt"
+	"strategy-design/money"
+	"sync"
+)
+
+// RefundBook remembers the charges a strategy has made and how much of each
+// has been refunded so far, so strategies can reject over-refunds.
+type RefundBook struct {
+	mu      sync.Mutex
+	charges map[string]*refundEntry
+	order   []string
+}
+
+type refundEntry struct {
+	method   string
+	charged  money.Money
+	refunded money.Money
+	fee      money.Money
+}
+
+func NewRefundBook() *RefundBook {
+	return &RefundBook{
+		charges: make(map[string]*refundEntry),
+	}
+}
+
+func (b *RefundBook) Record(charge *Receipt) {
+	if !charge.Succeeded() || charge.IsRefund() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.charges[charge.TransactionID]; !ok {
+		b.order = append(b.order, charge.TransactionID)
+	}
+	b.charges[charge.TransactionID] = &refundEntry{
+		method:   charge.Method,
+		charged:  charge.Amount,
+		refunded: money.Zero(charge.Amount.Currency()),
+		fee:      charge.Fee,
+	}
+}
+
+// Reserve books amount against the original charge, failing if it would
+// take the total refunded above what was charged. Call Release if the
+// refund then fails downstream.
+func (b *RefundBook) Reserve(original *Receipt, amount money.Money) error {
+	if !amount.IsPositive() {
+		return ErrInvalidAmount
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.charges[original.TransactionID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownCharge, original.TransactionID)
+	}
+	refunded, err := entry.refunded.Add(amount)
+	if err != nil {
+		return err
+	}
+	if cmp, _ := refunded.Cmp(entry.charged); cmp > 0 {
+		remaining, _ := entry.charged.Sub(entry.refunded)
+		return fmt.Errorf("%w: %s requested, %s remaining on %s", ErrOverRefund, amount, remaining, original.TransactionID)
+	}
+	entry.refunded = refunded
+	return nil
+}
+
+func (b *RefundBook) Release(original *Receipt, amount money.Money) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if entry, ok := b.charges[original.TransactionID]; ok {
+		if refunded, err := entry.refunded.Sub(amount); err == nil && !refunded.IsNegative() {
+			entry.refunded = refunded
+		}
+	}
+}
+
+// Refundable returns how much of the charge can still be refunded.
+func (b *RefundBook) Refundable(transactionID string) (money.Money, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.charges[transactionID]
+	if !ok {
+		return money.Money{}, fmt.Errorf("%w: %s", ErrUnknownCharge, transactionID)
+	}
+	return entry.charged.Sub(entry.refunded)
+}
+
+// Settlements reports every recorded charge in the order it was made.
+func (b *RefundBook) Settlements() []Settlement {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	settlements := make([]Settlement, 0, len(b.order))
+	for _, id := range b.order {
+		entry := b.charges[id]
+		settlements = append(settlements, Settlement{
+			Method:        entry.method,
+			TransactionID: id,
+			Gross:         entry.charged,
+			Refunded:      entry.refunded,
+			Fee:           entry.fee,
+		})
+	}
+	return settlements
+}
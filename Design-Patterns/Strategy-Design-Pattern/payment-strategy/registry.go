@@ -0,0 +1,106 @@
+package paymentstrategy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrUnknownStrategy = errors.New("unknown payment strategy")
+	ErrInvalidConfig   = errors.New("invalid payment strategy config")
+)
+
+// Config holds the key=value pairs from a strategy spec.
+type Config map[string]string
+
+// Factory builds a strategy from its config.
+type Factory func(cfg Config) (PaymentStrategy, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register makes a strategy available to New under name. Strategy packages
+// call it from init; registering the same name twice panics.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if factory == nil {
+		panic("paymentstrategy: Register factory is nil for " + name)
+	}
+	if _, dup := registry[name]; dup {
+		panic("paymentstrategy: Register called twice for " + name)
+	}
+	registry[name] = factory
+}
+
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New builds a strategy from a spec of the form "name:key=value,key=value",
+// for example "paypal:email=a@b.com". Values may not contain commas.
+func New(spec string) (PaymentStrategy, error) {
+	name, cfg, err := ParseSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q (registered: %s)", ErrUnknownStrategy, name, strings.Join(Registered(), ", "))
+	}
+	strategy, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("build %s: %w", name, err)
+	}
+	return strategy, nil
+}
+
+func ParseSpec(spec string) (string, Config, error) {
+	name, params, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: missing strategy name in %q", ErrInvalidConfig, spec)
+	}
+	cfg := make(Config)
+	if strings.TrimSpace(params) == "" {
+		return name, cfg, nil
+	}
+	for _, pair := range strings.Split(params, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return "", nil, fmt.Errorf("%w: bad parameter %q in %q", ErrInvalidConfig, pair, spec)
+		}
+		if _, dup := cfg[key]; dup {
+			return "", nil, fmt.Errorf("%w: duplicate parameter %q in %q", ErrInvalidConfig, key, spec)
+		}
+		cfg[key] = strings.TrimSpace(value)
+	}
+	return name, cfg, nil
+}
+
+func (c Config) Get(key string) string {
+	return c[key]
+}
+
+func (c Config) Require(key string) (string, error) {
+	value := c[key]
+	if value == "" {
+		return "", fmt.Errorf("%w: %q is required", ErrInvalidConfig, key)
+	}
+	return value, nil
+}
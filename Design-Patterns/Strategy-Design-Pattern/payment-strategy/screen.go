@@ -0,0 +1,25 @@
+package paymentstrategy
+
+import (
+	"context"
+	"strategy-design/money"
+)
+
+// Screen vets a charge of amount on instrument before it is made and
+// returns an error when the charge must not go ahead.
+type Screen func(instrument Instrument, amount money.Money) error
+
+type screenKey struct{}
+
+// WithScreen returns a context carrying screen. Strategies that may charge
+// an instrument other than the one they identify, such as a fallback chain,
+// run it before each such charge.
+func WithScreen(ctx context.Context, screen Screen) context.Context {
+	return context.WithValue(ctx, screenKey{}, screen)
+}
+
+// Screening returns the screen carried by ctx, or nil if none was set.
+func Screening(ctx context.Context) Screen {
+	screen, _ := ctx.Value(screenKey{}).(Screen)
+	return screen
+}
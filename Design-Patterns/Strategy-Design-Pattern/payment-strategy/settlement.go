@@ -0,0 +1,19 @@
+package paymentstrategy
+
+import "strategy-design/money"
+
+// Settlement is what a payment method reports for one charge: the gross
+// amount collected, how much of it has been refunded and the fee it kept.
+type Settlement struct {
+	Method        string
+	TransactionID string
+	Gross         money.Money
+	Refunded      money.Money
+	Fee           money.Money
+}
+
+// SettlementReporter is implemented by strategies that can report the
+// charges they made, for reconciliation against the books.
+type SettlementReporter interface {
+	Settlements() []Settlement
+}
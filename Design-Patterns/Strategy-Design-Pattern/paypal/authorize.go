@@ -0,0 +1,79 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+func (p *Paypal) Authorizations() *paymentstrategy.AuthorizationBook {
+	return p.auths
+}
+
+// Authorize holds the amount against the account. The gateway has no
+// authorization endpoint, so with a gateway the hold is kept here and the
+// gateway is only charged on Capture.
+func (p *Paypal) Authorize(ctx context.Context, amount money.Money) (*paymentstrategy.Authorization, error) {
+	if p.email == "" {
+		return nil, paymentstrategy.AuthorizationFailed(Method, paymentstrategy.ErrInvalidInstrument)
+	}
+	if !amount.IsPositive() {
+		return nil, paymentstrategy.AuthorizationFailed(Method, paymentstrategy.ErrInvalidAmount)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return nil, paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	auth := p.auths.Open(Method, p.email, amount, "")
+	fmt.Printf("Authorized %s on Paypal: %s\n", amount, p.email)
+	return auth, nil
+}
+
+func (p *Paypal) Capture(ctx context.Context, auth *paymentstrategy.Authorization, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	if p.settlement != "" {
+		settled, rate, err := exchangerate.Convert(p.rates, amount, p.settlement)
+		if err != nil {
+			return receipt.Fail(err)
+		}
+		receipt.Settle(settled, rate)
+	}
+	if _, err := p.auths.Capture(auth.ID, amount); err != nil {
+		return receipt.Fail(err)
+	}
+	if p.gateway != nil {
+		resp, err := p.gateway.Charge(ctx, ChargeRequest{
+			Email:     p.email,
+			Amount:    receipt.SettledAmount.Amount(),
+			Currency:  string(receipt.SettledAmount.Currency()),
+			Reference: auth.ID,
+		})
+		if err != nil {
+			// The charge is sent with the authorization ID as its reference,
+			// so capturing again after an ambiguous failure returns the
+			// original charge instead of taking the money twice.
+			p.auths.Reopen(auth.ID)
+			return receipt.Fail(err)
+		}
+		receipt.TransactionID = resp.ID
+	}
+	receipt.Fee = p.fees.Fee(amount)
+	fmt.Printf("Captured %s of %s on Paypal: %s\n", amount, auth.Amount, p.email)
+	p.refunds.Record(receipt)
+	return receipt, nil
+}
+
+func (p *Paypal) Void(ctx context.Context, auth *paymentstrategy.Authorization) error {
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	if _, err := p.auths.Void(auth.ID); err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	fmt.Printf("Voided %s on Paypal: %s\n", auth.Amount, p.email)
+	return nil
+}
@@ -0,0 +1,21 @@
+package paypal
+
+import (
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// DefaultFees is what PayPal charges cost unless SetFees says otherwise.
+var DefaultFees = paymentstrategy.FeeModel{
+	{Currency: money.USD, PercentBPS: 349, Fixed: money.MustParse("0.49", money.USD), MaxAmount: money.MustParse("10000.00", money.USD)},
+	{Currency: money.EUR, PercentBPS: 340, Fixed: money.MustParse("0.35", money.EUR)},
+	{Currency: money.GBP, PercentBPS: 290, Fixed: money.MustParse("0.30", money.GBP)},
+}
+
+func (p *Paypal) Fees() paymentstrategy.FeeModel {
+	return p.fees
+}
+
+func (p *Paypal) SetFees(fees paymentstrategy.FeeModel) {
+	p.fees = fees
+}
@@ -0,0 +1,137 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+	"time"
+)
+
+const (
+	ChargesPath = "/v1/charges"
+	RefundsPath = "/v1/refunds"
+
+	StatusApproved = "approved"
+	StatusDeclined = "declined"
+
+	ReasonInsufficientFunds = "insufficient_funds"
+	ReasonInvalidAccount    = "invalid_account"
+)
+
+// Amounts on the wire are in minor units of Currency.
+type ChargeRequest struct {
+	Email     string `json:"email"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+type RefundRequest struct {
+	ChargeID  string `json:"charge_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Reference string `json:"reference"`
+}
+
+type GatewayResponse struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Gateway moves money for the Paypal strategy. Implementations map
+// failures onto the paymentstrategy Err* values.
+type Gateway interface {
+	Charge(ctx context.Context, req ChargeRequest) (*GatewayResponse, error)
+	Refund(ctx context.Context, req RefundRequest) (*GatewayResponse, error)
+}
+
+// HTTPGateway talks JSON over HTTP to a payment gateway API.
+type HTTPGateway struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewHTTPGateway(baseURL string, client *http.Client) *HTTPGateway {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &HTTPGateway{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  client,
+	}
+}
+
+func (g *HTTPGateway) Charge(ctx context.Context, req ChargeRequest) (*GatewayResponse, error) {
+	return g.post(ctx, ChargesPath, req)
+}
+
+func (g *HTTPGateway) Refund(ctx context.Context, req RefundRequest) (*GatewayResponse, error) {
+	return g.post(ctx, RefundsPath, req)
+}
+
+func (g *HTTPGateway) post(ctx context.Context, path string, body any) (*GatewayResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(httpReq)
+	if err != nil {
+		if cancelled := paymentstrategy.Cancelled(ctx); cancelled != nil {
+			return nil, cancelled
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("%w: %v", paymentstrategy.ErrTimeout, err)
+		}
+		return nil, fmt.Errorf("%w: %v", paymentstrategy.ErrGatewayUnavailable, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading response: %v", paymentstrategy.ErrGatewayUnavailable, err)
+	}
+
+	switch {
+	case resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, fmt.Errorf("%w: %s returned %s", paymentstrategy.ErrGatewayUnavailable, path, resp.Status)
+	case resp.StatusCode == http.StatusBadRequest:
+		return nil, fmt.Errorf("%w: %s", paymentstrategy.ErrInvalidAmount, strings.TrimSpace(string(data)))
+	case resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPaymentRequired:
+		return nil, fmt.Errorf("paypal gateway %s returned %s", path, resp.Status)
+	}
+
+	var out GatewayResponse
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("paypal gateway %s: bad response: %w", path, err)
+	}
+	if out.Status != StatusApproved {
+		return &out, declineError(out.Reason)
+	}
+	return &out, nil
+}
+
+func declineError(reason string) error {
+	switch reason {
+	case ReasonInsufficientFunds:
+		return paymentstrategy.ErrInsufficientFunds
+	case ReasonInvalidAccount:
+		return paymentstrategy.ErrInvalidInstrument
+	}
+	if reason == "" {
+		return paymentstrategy.ErrDeclined
+	}
+	return fmt.Errorf("%w: %s", paymentstrategy.ErrDeclined, reason)
+}
@@ -0,0 +1,208 @@
+// Package mockgateway is an in-process stand-in for the PayPal gateway API.
+// Server is an http.Handler, so it can be mounted with httptest.NewServer.
+package mockgateway
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strategy-design/paypal"
+	"sync"
+	"time"
+)
+
+type Charge struct {
+	paypal.ChargeRequest
+	ID       string
+	Refunded int64
+}
+
+type Server struct {
+	mu          sync.Mutex
+	latency     time.Duration
+	failNext    int
+	declined    map[string]string
+	declineOver int64
+	charges     map[string]*Charge
+	refunds     []paypal.RefundRequest
+	// Responses already given, by request reference, so that a repeated
+	// request gets the original answer instead of moving money again.
+	chargeRefs map[string]*Charge
+	refundRefs map[string]refundReply
+}
+
+type refundReply struct {
+	req paypal.RefundRequest
+	id  string
+}
+
+func NewServer() *Server {
+	return &Server{
+		declined:   make(map[string]string),
+		charges:    make(map[string]*Charge),
+		chargeRefs: make(map[string]*Charge),
+		refundRefs: make(map[string]refundReply),
+	}
+}
+
+// SetLatency delays every response by d.
+func (s *Server) SetLatency(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latency = d
+}
+
+// FailNext makes the next n requests answer 503.
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+// Decline makes every charge from email fail with reason, e.g.
+// paypal.ReasonInsufficientFunds.
+func (s *Server) Decline(email, reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.declined[email] = reason
+}
+
+// Allow undoes Decline for email.
+func (s *Server) Allow(email string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.declined, email)
+}
+
+// DeclineOver declines any charge above limit minor units; 0 disables it.
+func (s *Server) DeclineOver(limit int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.declineOver = limit
+}
+
+func (s *Server) Charges() []Charge {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	charges := make([]Charge, 0, len(s.charges))
+	for _, c := range s.charges {
+		charges = append(charges, *c)
+	}
+	return charges
+}
+
+func (s *Server) Refunds() []paypal.RefundRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]paypal.RefundRequest(nil), s.refunds...)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	latency := s.latency
+	fail := s.failNext > 0
+	if fail {
+		s.failNext--
+	}
+	s.mu.Unlock()
+
+	if latency > 0 {
+		select {
+		case <-time.After(latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if fail {
+		http.Error(w, "gateway temporarily unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.URL.Path {
+	case paypal.ChargesPath:
+		s.charge(w, r)
+	case paypal.RefundsPath:
+		s.refund(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (s *Server) charge(w http.ResponseWriter, r *http.Request) {
+	var req paypal.ChargeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 || req.Currency == "" {
+		http.Error(w, "invalid charge request", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prior, ok := s.chargeRefs[req.Reference]; ok && req.Reference != "" {
+		if prior.ChargeRequest != req {
+			http.Error(w, "reference already used for a different charge", http.StatusConflict)
+			return
+		}
+		respond(w, http.StatusOK, paypal.GatewayResponse{ID: prior.ID, Status: paypal.StatusApproved})
+		return
+	}
+	if reason, ok := s.declined[req.Email]; ok {
+		respond(w, http.StatusPaymentRequired, paypal.GatewayResponse{Status: paypal.StatusDeclined, Reason: reason})
+		return
+	}
+	if s.declineOver > 0 && req.Amount > s.declineOver {
+		respond(w, http.StatusPaymentRequired, paypal.GatewayResponse{Status: paypal.StatusDeclined, Reason: "over_limit"})
+		return
+	}
+	charge := &Charge{ChargeRequest: req, ID: newID("PAY-")}
+	s.charges[charge.ID] = charge
+	if req.Reference != "" {
+		s.chargeRefs[req.Reference] = charge
+	}
+	respond(w, http.StatusOK, paypal.GatewayResponse{ID: charge.ID, Status: paypal.StatusApproved})
+}
+
+func (s *Server) refund(w http.ResponseWriter, r *http.Request) {
+	var req paypal.RefundRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Amount <= 0 {
+		http.Error(w, "invalid refund request", http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if prior, ok := s.refundRefs[req.Reference]; ok && req.Reference != "" {
+		if prior.req != req {
+			http.Error(w, "reference already used for a different refund", http.StatusConflict)
+			return
+		}
+		respond(w, http.StatusOK, paypal.GatewayResponse{ID: prior.id, Status: paypal.StatusApproved})
+		return
+	}
+	charge, ok := s.charges[req.ChargeID]
+	if !ok || charge.Currency != req.Currency || charge.Refunded+req.Amount > charge.Amount {
+		respond(w, http.StatusPaymentRequired, paypal.GatewayResponse{Status: paypal.StatusDeclined, Reason: "refund_rejected"})
+		return
+	}
+	charge.Refunded += req.Amount
+	s.refunds = append(s.refunds, req)
+	id := newID("REF-")
+	if req.Reference != "" {
+		s.refundRefs[req.Reference] = refundReply{req: req, id: id}
+	}
+	respond(w, http.StatusOK, paypal.GatewayResponse{ID: id, Status: paypal.StatusApproved})
+}
+
+func respond(w http.ResponseWriter, status int, body paypal.GatewayResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func newID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + hex.EncodeToString(b)
+}
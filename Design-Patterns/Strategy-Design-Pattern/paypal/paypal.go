@@ -1,17 +1,121 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+const Method = "paypal"
 
 type Paypal struct {
-    email string
+	email      string
+	settlement money.Currency
+	rates      exchangerate.Provider
+	refunds    *paymentstrategy.RefundBook
+	gateway    Gateway
+	auths      *paymentstrategy.AuthorizationBook
+	fees       paymentstrategy.FeeModel
 }
 
 func NewPaypal(email string) *Paypal {
-    return &Paypal{
-        email: email,
-    }
+	return &Paypal{
+		email:   email,
+		refunds: paymentstrategy.NewRefundBook(),
+		auths:   paymentstrategy.NewAuthorizationBook(paymentstrategy.DefaultAuthorizationTTL),
+		fees:    DefaultFees,
+	}
+}
+
+// NewPaypalWithGateway charges through gateway instead of settling locally.
+func NewPaypalWithGateway(email string, gateway Gateway) *Paypal {
+	p := NewPaypal(email)
+	p.gateway = gateway
+	return p
+}
+
+// SetSettlementCurrency makes the strategy settle every charge in currency,
+// converting with rates. Without it charges settle in the cart's currency.
+func (p *Paypal) SetSettlementCurrency(currency money.Currency, rates exchangerate.Provider) {
+	p.settlement = currency
+	p.rates = rates
+}
+
+func (p *Paypal) Identify() paymentstrategy.Instrument {
+	return paymentstrategy.Instrument{Method: Method, ID: p.email, Display: p.email, Email: p.email}
+}
+
+func (p *Paypal) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	if p.email == "" {
+		return receipt.Fail(paymentstrategy.ErrInvalidInstrument)
+	}
+	if !amount.IsPositive() {
+		return receipt.Fail(paymentstrategy.ErrInvalidAmount)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	if p.settlement != "" {
+		settled, rate, err := exchangerate.Convert(p.rates, amount, p.settlement)
+		if err != nil {
+			return receipt.Fail(err)
+		}
+		receipt.Settle(settled, rate)
+	}
+	if p.gateway != nil {
+		resp, err := p.gateway.Charge(ctx, ChargeRequest{
+			Email:     p.email,
+			Amount:    receipt.SettledAmount.Amount(),
+			Currency:  string(receipt.SettledAmount.Currency()),
+			Reference: paymentstrategy.Reference(ctx, receipt.TransactionID),
+		})
+		if err != nil {
+			return receipt.Fail(err)
+		}
+		receipt.TransactionID = resp.ID
+	}
+	receipt.Fee = p.fees.Fee(amount)
+	fmt.Printf("Paid %s using Paypal: %s\n", amount, p.email)
+	p.refunds.Record(receipt)
+	return receipt, nil
 }
 
-func (p *Paypal) Pay(amount float64) {
-    fmt.Printf("Paid %.2f using Paypal: %s\n", amount, p.email)
-}
\ No newline at end of file
+func (p *Paypal) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	refund := paymentstrategy.NewRefundReceipt(original, amount)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return refund.Fail(err)
+	}
+	if err := p.refunds.Reserve(original, amount); err != nil {
+		return refund.Fail(err)
+	}
+	if p.gateway != nil {
+		resp, err := p.gateway.Refund(ctx, RefundRequest{
+			ChargeID:  original.TransactionID,
+			Amount:    refund.SettledAmount.Amount(),
+			Currency:  string(refund.SettledAmount.Currency()),
+			Reference: paymentstrategy.Reference(ctx, refund.TransactionID),
+		})
+		if err != nil {
+			// Only a refund the gateway answered, or rejected outright, is
+			// known not to have been made. After a timeout or cancellation
+			// it may have been, so the amount stays reserved.
+			if resp != nil || errors.Is(err, paymentstrategy.ErrInvalidAmount) {
+				p.refunds.Release(original, amount)
+			}
+			return refund.Fail(err)
+		}
+		refund.TransactionID = resp.ID
+	}
+	fmt.Printf("Refunded %s to Paypal: %s\n", amount, p.email)
+	return refund, nil
+}
+
+// Settlements reports every charge made to this account and how much of each
+// has been refunded.
+func (p *Paypal) Settlements() []paymentstrategy.Settlement {
+	return p.refunds.Settlements()
+}
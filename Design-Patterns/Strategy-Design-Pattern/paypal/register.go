@@ -0,0 +1,29 @@
+package paypal
+
+import (
+	exchangerate "strategy-design/exchange-rate"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// Spec: paypal:email=...[,gateway=http://host:port][,settle=INR,rates=rates.json]
+func init() {
+	paymentstrategy.Register(Method, func(cfg paymentstrategy.Config) (paymentstrategy.PaymentStrategy, error) {
+		email, err := cfg.Require("email")
+		if err != nil {
+			return nil, err
+		}
+		p := NewPaypal(email)
+		if url := cfg.Get("gateway"); url != "" {
+			p = NewPaypalWithGateway(email, NewHTTPGateway(url, nil))
+		}
+		if settle := cfg.Get("settle"); settle != "" {
+			rates, err := exchangerate.NewFileProvider(cfg.Get("rates"))
+			if err != nil {
+				return nil, err
+			}
+			p.SetSettlementCurrency(money.Currency(settle), rates)
+		}
+		return p, nil
+	})
+}
@@ -0,0 +1,258 @@
+package retrystrategy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNoStrategies = errors.New("no payment strategies configured")
+	// ErrOutcomeUnknown means a charge timed out and may still have gone
+	// through, so the chain stopped rather than charge another method.
+	ErrOutcomeUnknown = errors.New("payment outcome unknown")
+)
+
+type Policy struct {
+	// MaxAttempts is the number of tries per strategy, including the first.
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Multiplier  float64
+	// Jitter is the fraction (0..1) of each delay that is randomised.
+	Jitter float64
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.5,
+	}
+}
+
+// RetryStrategy retries transient failures of each strategy with
+// exponential backoff and then falls back to the next one in order. It
+// only falls back once a strategy has definitely not charged: if its last
+// word was a timeout, Pay stops with ErrOutcomeUnknown instead.
+type RetryStrategy struct {
+	policy     Policy
+	strategies []paymentstrategy.PaymentStrategy
+	sleep      func(context.Context, time.Duration) error
+	random     func() float64
+
+	mu      sync.Mutex
+	charged map[string]paymentstrategy.PaymentStrategy
+}
+
+func NewRetryStrategy(policy Policy, primary paymentstrategy.PaymentStrategy, fallbacks ...paymentstrategy.PaymentStrategy) *RetryStrategy {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 1
+	}
+	strategies := make([]paymentstrategy.PaymentStrategy, 0, len(fallbacks)+1)
+	for _, s := range append([]paymentstrategy.PaymentStrategy{primary}, fallbacks...) {
+		if s != nil {
+			strategies = append(strategies, s)
+		}
+	}
+	return &RetryStrategy{
+		policy:     policy,
+		strategies: strategies,
+		sleep:      sleep,
+		random:     rand.Float64,
+		charged:    make(map[string]paymentstrategy.PaymentStrategy),
+	}
+}
+
+// SetSleep replaces the backoff wait, mainly so tests and demos need not
+// wait. sleep must return early with ctx's error once ctx is done.
+func (r *RetryStrategy) SetSleep(sleep func(ctx context.Context, d time.Duration) error) {
+	r.sleep = sleep
+}
+
+func (r *RetryStrategy) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	if len(r.strategies) == 0 {
+		return nil, ErrNoStrategies
+	}
+	var (
+		attempts []paymentstrategy.Attempt
+		receipt  *paymentstrategy.Receipt
+		err      error
+	)
+	// Every attempt against one strategy carries the same reference so a
+	// gateway can tell a retry from a new charge; fallbacks get their own.
+	reference := paymentstrategy.Reference(ctx, paymentstrategy.NewTransactionID())
+	for i, strategy := range r.strategies {
+		if i > 0 {
+			if err := r.screen(ctx, strategy, amount); err != nil {
+				if receipt != nil {
+					receipt.Attempts = attempts
+				}
+				return receipt, err
+			}
+		}
+		attemptCtx := paymentstrategy.WithReference(ctx, fmt.Sprintf("%s-%d", reference, i+1))
+		// inDoubt is set while a timed-out attempt has not been answered
+		// definitively by a later one under the same reference.
+		inDoubt := false
+		for n := 1; n <= r.policy.MaxAttempts; n++ {
+			if n > 1 {
+				if cancelled := r.sleep(ctx, r.backoff(n-1)); cancelled != nil {
+					if receipt != nil {
+						receipt.Attempts = attempts
+						receipt.Status = paymentstrategy.StatusCancelled
+					}
+					return receipt, fmt.Errorf("%w: %w", paymentstrategy.ErrCancelled, cancelled)
+				}
+			}
+			receipt, err = strategy.Pay(attemptCtx, amount)
+			attempts = append(attempts, attemptFor(receipt, err, n))
+			if err == nil {
+				receipt.Attempts = attempts
+				r.remember(receipt, strategy)
+				return receipt, nil
+			}
+			if !paymentstrategy.IsTransient(err) {
+				inDoubt = false
+				break
+			}
+			if errors.Is(err, paymentstrategy.ErrTimeout) {
+				inDoubt = true
+			}
+		}
+		if inDoubt {
+			if receipt != nil {
+				receipt.Attempts = attempts
+			}
+			return receipt, fmt.Errorf("%w after %d attempt(s): %w", ErrOutcomeUnknown, len(attempts), err)
+		}
+		if errors.Is(err, paymentstrategy.ErrInvalidAmount) || paymentstrategy.IsCancelled(err) {
+			break
+		}
+	}
+	if receipt != nil {
+		receipt.Attempts = attempts
+	}
+	return receipt, fmt.Errorf("payment failed after %d attempt(s): %w", len(attempts), err)
+}
+
+// Refund sends the refund to whichever strategy actually took the charge.
+func (r *RetryStrategy) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	r.mu.Lock()
+	strategy, ok := r.charged[original.TransactionID]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", paymentstrategy.ErrUnknownCharge, original.TransactionID)
+	}
+	refunder, ok := strategy.(paymentstrategy.Refunder)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", original.Method, paymentstrategy.ErrRefundUnsupported)
+	}
+	return refunder.Refund(ctx, original, amount)
+}
+
+// Settlements reports the charges of every strategy that can report them.
+func (r *RetryStrategy) Settlements() []paymentstrategy.Settlement {
+	var settlements []paymentstrategy.Settlement
+	for _, strategy := range r.strategies {
+		if reporter, ok := strategy.(paymentstrategy.SettlementReporter); ok {
+			settlements = append(settlements, reporter.Settlements()...)
+		}
+	}
+	return settlements
+}
+
+// Identify describes the primary strategy's instrument, which is the one
+// the charge is expected to land on. Fallbacks are screened by Pay before
+// they are charged.
+func (r *RetryStrategy) Identify() paymentstrategy.Instrument {
+	if len(r.strategies) == 0 {
+		return paymentstrategy.Instrument{}
+	}
+	if id, ok := r.strategies[0].(paymentstrategy.Identifier); ok {
+		return id.Identify()
+	}
+	return paymentstrategy.Instrument{}
+}
+
+// Fees reports the primary strategy's fee model, or nil when the chain is
+// empty or the primary declares none; fallbacks may cost more.
+func (r *RetryStrategy) Fees() paymentstrategy.FeeModel {
+	if len(r.strategies) == 0 {
+		return nil
+	}
+	if quoter, ok := r.strategies[0].(paymentstrategy.FeeQuoter); ok {
+		return quoter.Fees()
+	}
+	return nil
+}
+
+// backoff returns the delay before retry number n (1-based).
+func (r *RetryStrategy) backoff(n int) time.Duration {
+	delay := float64(r.policy.BaseDelay) * math.Pow(r.policy.Multiplier, float64(n-1))
+	if ceiling := float64(r.policy.MaxDelay); ceiling > 0 && delay > ceiling {
+		delay = ceiling
+	}
+	jitter := min(max(r.policy.Jitter, 0), 1)
+	delay -= delay * jitter * r.random()
+	return time.Duration(delay)
+}
+
+// screen runs the screen carried by ctx against a fallback before it is
+// charged. The primary is screened by the caller through Identify.
+func (r *RetryStrategy) screen(ctx context.Context, strategy paymentstrategy.PaymentStrategy, amount money.Money) error {
+	screen := paymentstrategy.Screening(ctx)
+	if screen == nil {
+		return nil
+	}
+	var instrument paymentstrategy.Instrument
+	if id, ok := strategy.(paymentstrategy.Identifier); ok {
+		instrument = id.Identify()
+	}
+	return screen(instrument, amount)
+}
+
+func (r *RetryStrategy) remember(receipt *paymentstrategy.Receipt, strategy paymentstrategy.PaymentStrategy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.charged[receipt.TransactionID] = strategy
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func attemptFor(receipt *paymentstrategy.Receipt, err error, n int) paymentstrategy.Attempt {
+	attempt := paymentstrategy.Attempt{Number: n, Status: paymentstrategy.StatusSucceeded, Timestamp: time.Now()}
+	if receipt != nil {
+		attempt.Method = receipt.Method
+		attempt.TransactionID = receipt.TransactionID
+		attempt.Status = receipt.Status
+		attempt.Timestamp = receipt.Timestamp
+	}
+	if err != nil {
+		attempt.Error = err.Error()
+		if receipt == nil {
+			attempt.Status = paymentstrategy.StatusFailed
+		}
+	}
+	return attempt
+}
@@ -0,0 +1,118 @@
+package shoppingcart
+
+import (
+	"context"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+type authorization struct {
+	strategy paymentstrategy.PaymentStrategy
+	auth     *paymentstrategy.Authorization
+	quote    *Quote
+}
+
+// AuthorizeCheckout holds the quoted total on the selected payment method
+// without charging it, for orders that are only charged when they ship.
+// Take the money with CaptureShipment or let it go with VoidAuthorization.
+func (s *ShoppingCart) AuthorizeCheckout(ctx context.Context) (*paymentstrategy.Authorization, error) {
+	if s.strategy == nil {
+		return nil, ErrNoPaymentMethod
+	}
+	if len(s.items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	authorizer, ok := s.strategy.(paymentstrategy.Authorizer)
+	if !ok {
+		return nil, paymentstrategy.ErrAuthorizeUnsupported
+	}
+	quote, err := s.Quote()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.screen(s.strategy, quote.Total); err != nil {
+		return nil, err
+	}
+	auth, err := authorizer.Authorize(ctx, quote.Total)
+	if err != nil {
+		return nil, err
+	}
+	s.authorizations = append(s.authorizations, &authorization{strategy: s.strategy, auth: auth, quote: quote})
+	return auth, nil
+}
+
+// CaptureShipment charges amount against an authorization when the order
+// ships. Capturing less than was authorized, for a partial shipment,
+// releases the rest; an authorization can only be captured once.
+func (s *ShoppingCart) CaptureShipment(ctx context.Context, authID string, amount money.Money) (*paymentstrategy.Receipt, error) {
+	a, err := s.authorization(authID)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := a.strategy.(paymentstrategy.Authorizer).Capture(ctx, a.auth, amount)
+	if err != nil {
+		s.refreshAuthorization(a)
+		return receipt, err
+	}
+	if amount.Equal(a.auth.Amount) {
+		a.quote.annotate(receipt)
+	}
+	a.auth.Status = paymentstrategy.AuthorizationCaptured
+	a.auth.Captured = amount
+	return receipt, s.recordPayment(a.strategy, receipt)
+}
+
+// CaptureShipmentFull captures everything that was authorized.
+func (s *ShoppingCart) CaptureShipmentFull(ctx context.Context, authID string) (*paymentstrategy.Receipt, error) {
+	a, err := s.authorization(authID)
+	if err != nil {
+		return nil, err
+	}
+	return s.CaptureShipment(ctx, authID, a.auth.Amount)
+}
+
+func (s *ShoppingCart) VoidAuthorization(ctx context.Context, authID string) error {
+	a, err := s.authorization(authID)
+	if err != nil {
+		return err
+	}
+	if err := a.strategy.(paymentstrategy.Authorizer).Void(ctx, a.auth); err != nil {
+		s.refreshAuthorization(a)
+		return err
+	}
+	a.auth.Status = paymentstrategy.AuthorizationVoided
+	return nil
+}
+
+// Authorizations returns every authorization made through the cart as it
+// stood after the cart last touched it.
+func (s *ShoppingCart) Authorizations() []paymentstrategy.Authorization {
+	auths := make([]paymentstrategy.Authorization, 0, len(s.authorizations))
+	for _, a := range s.authorizations {
+		auths = append(auths, *a.auth)
+	}
+	return auths
+}
+
+func (s *ShoppingCart) authorization(authID string) (*authorization, error) {
+	for _, a := range s.authorizations {
+		if a.auth.ID == authID {
+			return a, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", paymentstrategy.ErrUnknownAuthorization, authID)
+}
+
+// refreshAuthorization picks up an expiry the strategy noticed.
+func (s *ShoppingCart) refreshAuthorization(a *authorization) {
+	if book, ok := a.strategy.(interface {
+		Authorizations() *paymentstrategy.AuthorizationBook
+	}); ok {
+		if b := book.Authorizations(); b != nil {
+			if latest, err := b.Get(a.auth.ID); err == nil {
+				a.auth = latest
+			}
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package shoppingcart
+
+import (
+	"context"
+	"strategy-design/fraud"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// SetFraudScreen makes every checkout run through engine before any
+// strategy is charged. Anything short of an allow stops the checkout with
+// a *fraud.ScreeningError.
+func (s *ShoppingCart) SetFraudScreen(engine *fraud.Engine) {
+	s.fraud = engine
+}
+
+// screen evaluates a charge of amount on strategy. Strategies that cannot
+// identify their instrument are still screened on amount and destination.
+func (s *ShoppingCart) screen(strategy paymentstrategy.PaymentStrategy, amount money.Money) error {
+	if s.fraud == nil {
+		return nil
+	}
+	var instrument paymentstrategy.Instrument
+	if id, ok := strategy.(paymentstrategy.Identifier); ok {
+		instrument = id.Identify()
+	}
+	return s.screenInstrument(instrument, amount)
+}
+
+// screening hands the fraud screen to strategies that may fall back to an
+// instrument other than the one screened up front.
+func (s *ShoppingCart) screening(ctx context.Context) context.Context {
+	if s.fraud == nil {
+		return ctx
+	}
+	return paymentstrategy.WithScreen(ctx, s.screenInstrument)
+}
+
+func (s *ShoppingCart) screenInstrument(instrument paymentstrategy.Instrument, amount money.Money) error {
+	tx := fraud.Transaction{Instrument: instrument, Amount: amount, ShipTo: s.region.Country}
+	return s.fraud.Evaluate(tx).Err()
+}
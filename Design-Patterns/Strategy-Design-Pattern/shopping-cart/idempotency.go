@@ -0,0 +1,135 @@
+package shoppingcart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultIdempotencyRetention = 24 * time.Hour
+
+var (
+	ErrMissingIdempotencyKey = errors.New("idempotency key is required")
+	ErrIdempotencyKeyReused  = errors.New("idempotency key was already used for a different checkout")
+	ErrIdempotencyInDoubt    = errors.New("checkout with this key was cancelled after the charge was sent; its outcome is unknown")
+)
+
+// IdempotencyStore remembers checkout outcomes by client-supplied key for
+// the retention window. A store can be shared between carts.
+type IdempotencyStore struct {
+	mu        sync.Mutex
+	retention time.Duration
+	now       func() time.Time
+	entries   map[string]*idempotencyEntry
+}
+
+type idempotencyEntry struct {
+	fingerprint string
+	done        chan struct{}
+	receipt     *paymentstrategy.Receipt
+	err         error
+	storedAt    time.Time
+}
+
+func NewIdempotencyStore(retention time.Duration) *IdempotencyStore {
+	return &IdempotencyStore{
+		retention: retention,
+		now:       time.Now,
+		entries:   make(map[string]*idempotencyEntry),
+	}
+}
+
+func (s *IdempotencyStore) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Do runs charge once per key. Repeats with the same key wait for the first
+// call to finish (or for their own ctx to end) and get its result; repeats
+// with a different fingerprint are rejected. Outcomes that never reached
+// the payment strategy (no receipt) are not remembered so the client can
+// retry. A charge cancelled once it was handed to the strategy may still
+// have gone through, so the key is kept and repeats get
+// ErrIdempotencyInDoubt rather than a second charge.
+func (s *IdempotencyStore) Do(ctx context.Context, key, fingerprint string, charge func(context.Context) (*paymentstrategy.Receipt, error)) (*paymentstrategy.Receipt, error) {
+	if key == "" {
+		return nil, ErrMissingIdempotencyKey
+	}
+	s.mu.Lock()
+	s.purge()
+	if entry, ok := s.entries[key]; ok {
+		s.mu.Unlock()
+		if entry.fingerprint != fingerprint {
+			return nil, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, key)
+		}
+		select {
+		case <-entry.done:
+			return entry.receipt, entry.err
+		case <-ctx.Done():
+			return nil, paymentstrategy.Cancelled(ctx)
+		}
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	entry := &idempotencyEntry{fingerprint: fingerprint, done: make(chan struct{})}
+	s.entries[key] = entry
+	s.mu.Unlock()
+
+	receipt, err := charge(ctx)
+
+	s.mu.Lock()
+	entry.receipt, entry.err = receipt, err
+	entry.storedAt = s.now()
+	switch {
+	case receipt == nil:
+		delete(s.entries, key)
+	case receipt.Status == paymentstrategy.StatusCancelled:
+		entry.err = fmt.Errorf("%w: %s: %w", ErrIdempotencyInDoubt, key, err)
+	}
+	s.mu.Unlock()
+	close(entry.done)
+	return receipt, err
+}
+
+func (s *IdempotencyStore) purge() {
+	cutoff := s.now().Add(-s.retention)
+	for key, entry := range s.entries {
+		if !entry.storedAt.IsZero() && entry.storedAt.Before(cutoff) {
+			delete(s.entries, key)
+		}
+	}
+}
+
+// CheckoutWithKey behaves like Checkout, but a retry carrying the same key
+// returns the original receipt instead of charging again. The key is also
+// passed to the payment strategy as the gateway reference.
+func (s *ShoppingCart) CheckoutWithKey(ctx context.Context, key string) (*paymentstrategy.Receipt, error) {
+	if s.idempotency == nil {
+		s.idempotency = NewIdempotencyStore(DefaultIdempotencyRetention)
+	}
+	return s.idempotency.Do(paymentstrategy.WithReference(ctx, key), key, s.fingerprint(), s.Checkout)
+}
+
+func (s *ShoppingCart) SetIdempotencyStore(store *IdempotencyStore) {
+	s.idempotency = store
+}
+
+func (s *ShoppingCart) fingerprint() string {
+	var b strings.Builder
+	b.WriteString(s.Subtotal().String())
+	for _, item := range s.items {
+		fmt.Fprintf(&b, "|%s*%d@%d#%s", item.SKU, item.Quantity, item.UnitPrice.Amount(), item.TaxClass)
+	}
+	fmt.Fprintf(&b, "|ship:%s", s.region)
+	for _, code := range s.coupons {
+		fmt.Fprintf(&b, "|coupon:%s", code)
+	}
+	return b.String()
+}
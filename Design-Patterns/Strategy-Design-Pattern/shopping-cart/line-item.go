@@ -0,0 +1,24 @@
+package shoppingcart
+
+import "strategy-design/money"
+
+type LineItem struct {
+	SKU       string
+	Name      string
+	UnitPrice money.Money
+	Quantity  int
+	TaxClass  string
+}
+
+func NewLineItem(sku, name string, unitPrice money.Money, quantity int) *LineItem {
+	return &LineItem{
+		SKU:       sku,
+		Name:      name,
+		UnitPrice: unitPrice,
+		Quantity:  quantity,
+	}
+}
+
+func (l *LineItem) Total() money.Money {
+	return l.UnitPrice.Multiply(int64(l.Quantity))
+}
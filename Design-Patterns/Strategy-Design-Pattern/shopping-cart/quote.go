@@ -0,0 +1,187 @@
+package shoppingcart
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/discount"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strategy-design/tax"
+)
+
+var (
+	ErrNoDiscountEngine = errors.New("cart has no discount engine")
+	ErrNoShippingRegion = errors.New("cart has no shipping region")
+)
+
+// Quote is the priced cart: what is charged and how it was arrived at.
+// Total is Subtotal less Discounts plus any tax not already included in
+// the prices.
+type Quote struct {
+	Subtotal  money.Money
+	Discounts []paymentstrategy.Adjustment
+	Taxes     []paymentstrategy.Adjustment
+	Tax       money.Money
+	Total     money.Money
+	// Rejected lists applied coupons whose conditions the cart does not
+	// currently meet.
+	Rejected []discount.Rejection
+}
+
+func (s *ShoppingCart) Quote() (*Quote, error) {
+	subtotal := s.Subtotal()
+	quote := &Quote{Subtotal: subtotal, Tax: money.Zero(s.currency), Total: subtotal}
+	if err := s.applyDiscounts(quote); err != nil {
+		return nil, err
+	}
+	if err := s.applyTaxes(quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+func (s *ShoppingCart) applyDiscounts(quote *Quote) error {
+	if s.discounts == nil || len(s.coupons) == 0 {
+		return nil
+	}
+	result, err := s.discounts.Apply(s.coupons, s.discountItems(), s.currency)
+	if err != nil {
+		return err
+	}
+	for _, applied := range result.Applied {
+		quote.Discounts = append(quote.Discounts, paymentstrategy.Adjustment{
+			Code:        applied.Code,
+			Description: applied.Description,
+			SKU:         applied.SKU,
+			Amount:      applied.Amount,
+		})
+	}
+	quote.Total = result.Total
+	quote.Rejected = result.Rejected
+	return nil
+}
+
+// applyTaxes taxes each line on its price after discounts. Discounts tied
+// to a SKU come off that line; order-wide discounts are spread across the
+// lines in proportion to their value.
+func (s *ShoppingCart) applyTaxes(quote *Quote) error {
+	if s.taxes == nil {
+		return nil
+	}
+	if s.region.Country == "" {
+		return ErrNoShippingRegion
+	}
+	bases := make([]int64, len(s.items))
+	for i, item := range s.items {
+		bases[i] = item.Total().Amount()
+	}
+	var orderWide int64
+	for _, d := range quote.Discounts {
+		if d.SKU == "" {
+			orderWide += d.Amount.Amount()
+			continue
+		}
+		for i, item := range s.items {
+			if item.SKU == d.SKU {
+				bases[i] -= d.Amount.Amount()
+			}
+		}
+	}
+	if orderWide > 0 {
+		for i, share := range money.New(orderWide, s.currency).Allocate(bases...) {
+			bases[i] -= share.Amount()
+		}
+	}
+
+	lines := make([]tax.Line, 0, len(s.items))
+	for i, item := range s.items {
+		lines = append(lines, tax.Line{SKU: item.SKU, Class: item.TaxClass, Amount: money.New(bases[i], s.currency)})
+	}
+	taxes, err := s.taxes.Calculate(lines, s.region)
+	if err != nil {
+		return err
+	}
+	var added int64
+	for _, t := range taxes {
+		quote.Taxes = append(quote.Taxes, paymentstrategy.Adjustment{
+			Code:        "TAX",
+			Description: t.Label,
+			SKU:         t.SKU,
+			Amount:      t.Amount,
+			Inclusive:   t.Inclusive,
+		})
+		if !t.Inclusive {
+			added += t.Amount.Amount()
+		}
+	}
+	quote.Tax = money.New(added, s.currency)
+	quote.Total, err = quote.Total.Add(quote.Tax)
+	return err
+}
+
+func (s *ShoppingCart) SetTaxSelector(selector *tax.Selector) {
+	s.taxes = selector
+}
+
+func (s *ShoppingCart) SetShippingRegion(region tax.Region) {
+	s.region = region
+}
+
+func (s *ShoppingCart) SetTaxClass(sku, class string) error {
+	item := s.find(sku)
+	if item == nil {
+		return fmt.Errorf("%w: %s", ErrItemNotFound, sku)
+	}
+	item.TaxClass = class
+	return nil
+}
+
+func (s *ShoppingCart) SetDiscountEngine(engine *discount.Engine) {
+	s.discounts = engine
+}
+
+func (s *ShoppingCart) ApplyCoupon(code string) error {
+	if s.discounts == nil {
+		return ErrNoDiscountEngine
+	}
+	if err := s.discounts.Check(code, s.coupons); err != nil {
+		return err
+	}
+	for _, c := range s.coupons {
+		if c == code {
+			return nil
+		}
+	}
+	s.coupons = append(s.coupons, code)
+	return nil
+}
+
+func (s *ShoppingCart) RemoveCoupon(code string) {
+	for i, c := range s.coupons {
+		if c == code {
+			s.coupons = append(s.coupons[:i], s.coupons[i+1:]...)
+			return
+		}
+	}
+}
+
+func (s *ShoppingCart) Coupons() []string {
+	return append([]string(nil), s.coupons...)
+}
+
+func (s *ShoppingCart) discountItems() []discount.Item {
+	items := make([]discount.Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, discount.Item{SKU: item.SKU, UnitPrice: item.UnitPrice, Quantity: item.Quantity})
+	}
+	return items
+}
+
+func (q *Quote) annotate(receipt *paymentstrategy.Receipt) {
+	if receipt == nil {
+		return
+	}
+	receipt.Subtotal = q.Subtotal
+	receipt.Discounts = q.Discounts
+	receipt.Taxes = q.Taxes
+}
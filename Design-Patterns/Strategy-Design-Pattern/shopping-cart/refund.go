@@ -0,0 +1,174 @@
+package shoppingcart
+
+import (
+	"context"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+type payment struct {
+	strategy paymentstrategy.PaymentStrategy
+	receipt  *paymentstrategy.Receipt
+}
+
+// Payments returns the successful charges made through this cart. Pending
+// charges appear once they settle.
+func (s *ShoppingCart) Payments() []*paymentstrategy.Receipt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	receipts := make([]*paymentstrategy.Receipt, 0, len(s.payments))
+	for _, p := range s.payments {
+		receipts = append(receipts, p.receipt)
+	}
+	return receipts
+}
+
+// Refunds returns every refund attempted through this cart, including
+// rejected ones, in the order they were made.
+func (s *ShoppingCart) Refunds() []*paymentstrategy.Receipt {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*paymentstrategy.Receipt(nil), s.refunds...)
+}
+
+// Refund returns amount from the charge with the given transaction ID via
+// the strategy that made it.
+func (s *ShoppingCart) Refund(ctx context.Context, transactionID string, amount money.Money) (*paymentstrategy.Receipt, error) {
+	p, err := s.payment(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.refund(ctx, p, amount)
+}
+
+// RefundFull returns whatever is still refundable on the charge.
+func (s *ShoppingCart) RefundFull(ctx context.Context, transactionID string) (*paymentstrategy.Receipt, error) {
+	p, err := s.payment(transactionID)
+	if err != nil {
+		return nil, err
+	}
+	return s.refund(ctx, p, s.refundable(p.receipt))
+}
+
+func (s *ShoppingCart) Refundable(transactionID string) (money.Money, error) {
+	p, err := s.payment(transactionID)
+	if err != nil {
+		return money.Money{}, err
+	}
+	return s.refundable(p.receipt), nil
+}
+
+func (s *ShoppingCart) refund(ctx context.Context, p payment, amount money.Money) (*paymentstrategy.Receipt, error) {
+	refunder, ok := p.strategy.(paymentstrategy.Refunder)
+	if !ok {
+		return nil, fmt.Errorf("%s %s: %w", p.receipt.Method, p.receipt.TransactionID, paymentstrategy.ErrRefundUnsupported)
+	}
+	if err := s.reserve(p.receipt, amount); err != nil {
+		return nil, err
+	}
+	// The reservation is released only after the refund is recorded, so
+	// the amount is never missing from both.
+	defer s.release(p.receipt, amount)
+	receipt, err := refunder.Refund(ctx, p.receipt, amount)
+	if receipt == nil {
+		return nil, err
+	}
+	if ledgerErr := s.recordRefund(receipt); err == nil {
+		err = ledgerErr
+	}
+	return receipt, err
+}
+
+func (s *ShoppingCart) refundable(charge *paymentstrategy.Receipt) money.Money {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.refundableLocked(charge)
+}
+
+// refundableLocked is what is left of charge after successful refunds and
+// those still in flight. s.mu must be held.
+func (s *ShoppingCart) refundableLocked(charge *paymentstrategy.Receipt) money.Money {
+	remaining := charge.Amount.Amount() - s.reserved[charge.TransactionID]
+	for _, r := range s.refunds {
+		if r.Succeeded() && r.OriginalTransactionID == charge.TransactionID {
+			remaining -= r.Amount.Amount()
+		}
+	}
+	return money.New(remaining, charge.Amount.Currency())
+}
+
+// reserve sets amount aside on charge for a refund about to be made, so
+// concurrent refunds cannot together exceed the charge.
+func (s *ShoppingCart) reserve(charge *paymentstrategy.Receipt, amount money.Money) error {
+	if !amount.IsPositive() {
+		return paymentstrategy.ErrInvalidAmount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remaining := s.refundableLocked(charge)
+	if cmp, err := amount.Cmp(remaining); err != nil {
+		return err
+	} else if cmp > 0 {
+		return fmt.Errorf("%w: %s requested, %s remaining on %s", paymentstrategy.ErrOverRefund, amount, remaining, charge.TransactionID)
+	}
+	if s.reserved == nil {
+		s.reserved = make(map[string]int64)
+	}
+	s.reserved[charge.TransactionID] += amount.Amount()
+	return nil
+}
+
+func (s *ShoppingCart) release(charge *paymentstrategy.Receipt, amount money.Money) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reserved[charge.TransactionID] -= amount.Amount()
+	if s.reserved[charge.TransactionID] == 0 {
+		delete(s.reserved, charge.TransactionID)
+	}
+}
+
+func (s *ShoppingCart) payment(transactionID string) (payment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.payments {
+		if p.receipt.TransactionID == transactionID {
+			return p, nil
+		}
+	}
+	return payment{}, fmt.Errorf("%w: %s", paymentstrategy.ErrUnknownCharge, transactionID)
+}
+
+// recordPayment remembers a successful charge and books it in the ledger.
+// A ledger error does not undo the charge; the receipt stays valid.
+func (s *ShoppingCart) recordPayment(strategy paymentstrategy.PaymentStrategy, receipt *paymentstrategy.Receipt) error {
+	s.mu.Lock()
+	s.payments = append(s.payments, payment{strategy: strategy, receipt: receipt})
+	s.mu.Unlock()
+	if s.ledger == nil {
+		return nil
+	}
+	if err := s.ledger.RecordCharge(receipt); err != nil {
+		return fmt.Errorf("%w: %w", ErrLedger, err)
+	}
+	return nil
+}
+
+// recordRefund remembers a refund attempt. Only successful refunds of
+// charges recordPayment booked are posted; a rolled-back split leg was
+// never in the ledger, so its refund is not either.
+func (s *ShoppingCart) recordRefund(refund *paymentstrategy.Receipt) error {
+	s.mu.Lock()
+	s.refunds = append(s.refunds, refund)
+	s.mu.Unlock()
+	if s.ledger == nil || !refund.Succeeded() {
+		return nil
+	}
+	if _, err := s.payment(refund.OriginalTransactionID); err != nil {
+		return nil
+	}
+	if err := s.ledger.RecordRefund(refund); err != nil {
+		return fmt.Errorf("%w: %w", ErrLedger, err)
+	}
+	return nil
+}
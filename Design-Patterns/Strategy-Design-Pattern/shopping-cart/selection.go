@@ -0,0 +1,23 @@
+package shoppingcart
+
+import paymentstrategy "strategy-design/payment-strategy"
+
+// SelectCheapest prices the cart total on each strategy and switches the
+// cart to the eligible one with the lowest fee. The returned selection
+// shows every candidate's fee breakdown or why it was passed over; on
+// error the cart's payment method is left as it was.
+func (s *ShoppingCart) SelectCheapest(strategies ...paymentstrategy.PaymentStrategy) (*paymentstrategy.Selection, error) {
+	if len(s.items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	quote, err := s.Quote()
+	if err != nil {
+		return nil, err
+	}
+	selection, err := paymentstrategy.SelectCheapest(quote.Total, strategies...)
+	if err != nil {
+		return selection, err
+	}
+	s.strategy = selection.Chosen.Strategy
+	return selection, nil
+}
@@ -1,28 +1,176 @@
 package shoppingcart
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strategy-design/discount"
+	"strategy-design/fraud"
+	"strategy-design/ledger"
+	"strategy-design/money"
 	paymentstrategy "strategy-design/payment-strategy"
+	"strategy-design/tax"
+	"sync"
+)
+
+var (
+	ErrNoPaymentMethod = errors.New("select the payment method first")
+	ErrEmptyCart       = errors.New("cart is empty")
+	ErrItemNotFound    = errors.New("item not found in cart")
+	ErrInvalidQuantity = errors.New("quantity must be positive")
+	ErrInvalidPrice    = errors.New("unit price must not be negative")
+	ErrLedger          = errors.New("payment succeeded but could not be booked in the ledger")
 )
 
 type ShoppingCart struct {
-    payment paymentstrategy.PaymentStrategy
+	strategy paymentstrategy.PaymentStrategy
+	currency money.Currency
+	items    []*LineItem
+
+	// mu guards payments and refunds, which pending charges may add to
+	// from the goroutine that settles them, and the refund amounts
+	// reserved while a refund is in flight.
+	mu       sync.Mutex
+	payments []payment
+	refunds  []*paymentstrategy.Receipt
+	reserved map[string]int64
+
+	authorizations []*authorization
+
+	idempotency *IdempotencyStore
+	discounts   *discount.Engine
+	coupons     []string
+	taxes       *tax.Selector
+	region      tax.Region
+	ledger      *ledger.Ledger
+	fraud       *fraud.Engine
+}
+
+func NewShoppingCart(strategy paymentstrategy.PaymentStrategy, currency money.Currency) *ShoppingCart {
+	return &ShoppingCart{
+		strategy: strategy,
+		currency: currency,
+		items:    make([]*LineItem, 0),
+	}
+}
+
+// AddItem adds a new line or, if the SKU is already in the cart, increases
+// its quantity.
+func (s *ShoppingCart) AddItem(sku, name string, unitPrice money.Money, quantity int) error {
+	if quantity <= 0 {
+		return ErrInvalidQuantity
+	}
+	if unitPrice.IsNegative() {
+		return ErrInvalidPrice
+	}
+	if unitPrice.Currency() != s.currency {
+		return fmt.Errorf("%w: cart is in %s, %s priced in %s", money.ErrCurrencyMismatch, s.currency, sku, unitPrice.Currency())
+	}
+	if item := s.find(sku); item != nil {
+		item.Quantity += quantity
+		return nil
+	}
+	s.items = append(s.items, NewLineItem(sku, name, unitPrice, quantity))
+	return nil
+}
+
+func (s *ShoppingCart) RemoveItem(sku string) error {
+	for i, item := range s.items {
+		if item.SKU == sku {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrItemNotFound, sku)
 }
 
-func NewShoppingCart(strategy paymentstrategy.PaymentStrategy) *ShoppingCart {
-    return &ShoppingCart{
-        payment: strategy,
-    }
+// UpdateQuantity sets the quantity of a line; a quantity of zero removes it.
+func (s *ShoppingCart) UpdateQuantity(sku string, quantity int) error {
+	if quantity < 0 {
+		return ErrInvalidQuantity
+	}
+	if quantity == 0 {
+		return s.RemoveItem(sku)
+	}
+	item := s.find(sku)
+	if item == nil {
+		return fmt.Errorf("%w: %s", ErrItemNotFound, sku)
+	}
+	item.Quantity = quantity
+	return nil
 }
 
-func (s *ShoppingCart) Checkout(amount float64) {
-    if s.payment == nil {
-        fmt.Println("Select the payment method first")
-        return
-    }
-    s.payment.Pay(amount)
+func (s *ShoppingCart) Items() []LineItem {
+	items := make([]LineItem, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, *item)
+	}
+	return items
+}
+
+func (s *ShoppingCart) Currency() money.Currency {
+	return s.currency
+}
+
+func (s *ShoppingCart) Subtotal() money.Money {
+	var minor int64
+	for _, item := range s.items {
+		minor += item.Total().Amount()
+	}
+	return money.New(minor, s.currency)
+}
+
+// Checkout charges the quoted total once it clears fraud screening.
+// Cancelling ctx stops an in-flight payment and yields a receipt with
+// StatusCancelled.
+func (s *ShoppingCart) Checkout(ctx context.Context) (*paymentstrategy.Receipt, error) {
+	if s.strategy == nil {
+		return nil, ErrNoPaymentMethod
+	}
+	if len(s.items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	quote, err := s.Quote()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.screen(s.strategy, quote.Total); err != nil {
+		return nil, err
+	}
+	strategy := s.strategy
+	receipt, err := strategy.Pay(s.screening(ctx), quote.Total)
+	quote.annotate(receipt)
+	if err != nil {
+		return receipt, err
+	}
+	if receipt.Pending() {
+		// Nothing is booked until the charge settles, so a declined
+		// settlement leaves nothing to undo.
+		receipt.OnSettle(func(final *paymentstrategy.Receipt, _ error) {
+			if final.Succeeded() {
+				quote.annotate(final)
+				s.recordPayment(strategy, final)
+			}
+		})
+		return receipt, nil
+	}
+	return receipt, s.recordPayment(strategy, receipt)
+}
+
+// SetLedger books every charge and refund made through the cart in l.
+func (s *ShoppingCart) SetLedger(l *ledger.Ledger) {
+	s.ledger = l
 }
 
 func (s *ShoppingCart) SetPaymentMethod(newMethod paymentstrategy.PaymentStrategy) {
-    s.payment = newMethod
-}
\ No newline at end of file
+	s.strategy = newMethod
+}
+
+func (s *ShoppingCart) find(sku string) *LineItem {
+	for _, item := range s.items {
+		if item.SKU == sku {
+			return item
+		}
+	}
+	return nil
+}
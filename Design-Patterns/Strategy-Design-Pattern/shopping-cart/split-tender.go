@@ -0,0 +1,176 @@
+package shoppingcart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"strings"
+)
+
+var ErrUnderTendered = errors.New("tenders do not cover the cart total")
+
+// Tender is one leg of a split checkout. A zero Amount means "whatever is
+// still owed" when the leg is reached.
+type Tender struct {
+	Strategy paymentstrategy.PaymentStrategy
+	Amount   money.Money
+}
+
+func NewTender(strategy paymentstrategy.PaymentStrategy, amount money.Money) Tender {
+	return Tender{Strategy: strategy, Amount: amount}
+}
+
+func RemainderTender(strategy paymentstrategy.PaymentStrategy) Tender {
+	return Tender{Strategy: strategy}
+}
+
+type SplitReceipt struct {
+	Subtotal  money.Money
+	Discounts []paymentstrategy.Adjustment
+	Taxes     []paymentstrategy.Adjustment
+	Total     money.Money
+	Legs      []*paymentstrategy.Receipt
+}
+
+// SplitError reports the leg that failed and the outcome of refunding the
+// legs that had already been charged.
+type SplitError struct {
+	Leg         int
+	Err         error
+	Refunds     []*paymentstrategy.Receipt
+	RefundFails []error
+}
+
+func (e *SplitError) Error() string {
+	msg := fmt.Sprintf("split checkout failed on leg %d: %v", e.Leg+1, e.Err)
+	if len(e.RefundFails) > 0 {
+		fails := make([]string, 0, len(e.RefundFails))
+		for _, err := range e.RefundFails {
+			fails = append(fails, err.Error())
+		}
+		msg += "; rollback incomplete: " + strings.Join(fails, "; ")
+	}
+	return msg
+}
+
+func (e *SplitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *SplitError) RolledBack() bool {
+	return len(e.RefundFails) == 0
+}
+
+// CheckoutSplit charges the cart total across tenders in order. Every leg
+// is screened for fraud, and must be able to refund, before the first is
+// charged. If a leg
+// fails or ctx is cancelled, the legs already charged are refunded in
+// reverse order; the rollback itself is not bound by ctx.
+func (s *ShoppingCart) CheckoutSplit(ctx context.Context, tenders ...Tender) (*SplitReceipt, error) {
+	if len(s.items) == 0 {
+		return nil, ErrEmptyCart
+	}
+	quote, err := s.Quote()
+	if err != nil {
+		return nil, err
+	}
+	amounts, err := s.allocate(quote.Total, tenders)
+	if err != nil {
+		return nil, err
+	}
+	for i, tender := range tenders {
+		if amounts[i].IsZero() {
+			continue
+		}
+		if _, ok := tender.Strategy.(paymentstrategy.Refunder); !ok {
+			return nil, fmt.Errorf("tender %d: %w: legs must be refundable to roll back", i+1, paymentstrategy.ErrRefundUnsupported)
+		}
+		if err := s.screen(tender.Strategy, amounts[i]); err != nil {
+			return nil, fmt.Errorf("tender %d: %w", i+1, err)
+		}
+	}
+
+	result := &SplitReceipt{Subtotal: quote.Subtotal, Discounts: quote.Discounts, Taxes: quote.Taxes, Total: quote.Total}
+	charged := make([]paymentstrategy.PaymentStrategy, 0, len(tenders))
+	for i, tender := range tenders {
+		if amounts[i].IsZero() {
+			continue
+		}
+		receipt, err := tender.Strategy.Pay(s.screening(ctx), amounts[i])
+		if err == nil && receipt.Pending() {
+			// A leg that settles later cannot be rolled back in step with
+			// the others, so it is withdrawn and the split fails.
+			if err = receipt.CancelPending(); err == nil {
+				err = fmt.Errorf("%s: %w", receipt.Method, paymentstrategy.ErrDeferredSettlement)
+			}
+		}
+		if err == nil {
+			err = paymentstrategy.Cancelled(ctx)
+			if err != nil {
+				charged = append(charged, tender.Strategy)
+				result.Legs = append(result.Legs, receipt)
+			}
+		}
+		if err != nil {
+			splitErr := &SplitError{Leg: i, Err: err}
+			s.rollback(context.WithoutCancel(ctx), charged, result.Legs, splitErr)
+			return nil, splitErr
+		}
+		charged = append(charged, tender.Strategy)
+		result.Legs = append(result.Legs, receipt)
+	}
+	var ledgerErr error
+	for i, receipt := range result.Legs {
+		if err := s.recordPayment(charged[i], receipt); err != nil && ledgerErr == nil {
+			ledgerErr = err
+		}
+	}
+	return result, ledgerErr
+}
+
+func (s *ShoppingCart) allocate(total money.Money, tenders []Tender) ([]money.Money, error) {
+	amounts := make([]money.Money, len(tenders))
+	remaining := total.Amount()
+	for i, tender := range tenders {
+		if tender.Strategy == nil {
+			return nil, fmt.Errorf("tender %d: %w", i+1, ErrNoPaymentMethod)
+		}
+		if tender.Amount.IsNegative() {
+			return nil, fmt.Errorf("tender %d: %w", i+1, paymentstrategy.ErrInvalidAmount)
+		}
+		leg := remaining
+		if !tender.Amount.IsZero() {
+			if tender.Amount.Currency() != s.currency {
+				return nil, fmt.Errorf("tender %d: %w: cart is in %s", i+1, money.ErrCurrencyMismatch, s.currency)
+			}
+			leg = min(tender.Amount.Amount(), remaining)
+		}
+		amounts[i] = money.New(leg, s.currency)
+		remaining -= leg
+	}
+	if remaining > 0 {
+		return nil, fmt.Errorf("%w: %s short", ErrUnderTendered, money.New(remaining, s.currency))
+	}
+	return amounts, nil
+}
+
+func (s *ShoppingCart) rollback(ctx context.Context, strategies []paymentstrategy.PaymentStrategy, charged []*paymentstrategy.Receipt, splitErr *SplitError) {
+	for i := len(charged) - 1; i >= 0; i-- {
+		refunder, ok := strategies[i].(paymentstrategy.Refunder)
+		if !ok {
+			splitErr.RefundFails = append(splitErr.RefundFails, fmt.Errorf("%s %s: %w", charged[i].Method, charged[i].TransactionID, paymentstrategy.ErrRefundUnsupported))
+			continue
+		}
+		refund, err := refunder.Refund(ctx, charged[i], charged[i].Amount)
+		if refund != nil {
+			s.recordRefund(refund)
+		}
+		if err != nil {
+			splitErr.RefundFails = append(splitErr.RefundFails, err)
+			continue
+		}
+		splitErr.Refunds = append(splitErr.Refunds, refund)
+	}
+}
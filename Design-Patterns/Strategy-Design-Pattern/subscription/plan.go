@@ -0,0 +1,81 @@
+package subscription
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	"time"
+)
+
+var ErrInvalidPlan = errors.New("invalid subscription plan")
+
+// Interval is a billing period length in calendar months plus days.
+type Interval struct {
+	Months int
+	Days   int
+}
+
+var (
+	Monthly = Interval{Months: 1}
+	Yearly  = Interval{Months: 12}
+)
+
+func EveryDays(days int) Interval {
+	return Interval{Days: days}
+}
+
+func (i Interval) valid() bool {
+	return i.Months >= 0 && i.Days >= 0 && i.Months+i.Days > 0
+}
+
+func (i Interval) String() string {
+	switch {
+	case i == Monthly:
+		return "monthly"
+	case i == Yearly:
+		return "yearly"
+	case i.Months == 0:
+		return fmt.Sprintf("every %d day(s)", i.Days)
+	}
+	return fmt.Sprintf("every %d month(s) %d day(s)", i.Months, i.Days)
+}
+
+// after returns the end of the n-th period starting at anchor. Months are
+// counted from the anchor rather than chained, and clamped to the month's
+// last day, so a subscription started on Jan 31 renews on Feb 28 and then
+// Mar 31 instead of drifting.
+func (i Interval) after(anchor time.Time, n int) time.Time {
+	months := i.Months * n
+	year, month, day := anchor.Date()
+	target := time.Date(year, month+time.Month(months), 1, 0, 0, 0, 0, anchor.Location())
+	if last := daysIn(target); day > last {
+		day = last
+	}
+	hour, min, sec := anchor.Clock()
+	t := time.Date(target.Year(), target.Month(), day, hour, min, sec, anchor.Nanosecond(), anchor.Location())
+	return t.AddDate(0, 0, i.Days*n)
+}
+
+func daysIn(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+}
+
+type Plan struct {
+	ID       string
+	Name     string
+	Price    money.Money
+	Interval Interval
+}
+
+func (p Plan) Validate() error {
+	if p.ID == "" {
+		return fmt.Errorf("%w: missing ID", ErrInvalidPlan)
+	}
+	if !p.Price.IsPositive() {
+		return fmt.Errorf("%w: %s price must be positive", ErrInvalidPlan, p.ID)
+	}
+	if !p.Interval.valid() {
+		return fmt.Errorf("%w: %s has no billing interval", ErrInvalidPlan, p.ID)
+	}
+	return nil
+}
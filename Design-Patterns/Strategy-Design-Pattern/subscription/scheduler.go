@@ -0,0 +1,424 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownSubscription = errors.New("unknown subscription")
+	ErrCancelled           = errors.New("subscription is cancelled")
+)
+
+// Dunning decides how a failed renewal is chased. Retries are spaced by
+// RetryAfter, measured from the original failure; the customer keeps
+// access for GracePeriod after it. When the retries run out the
+// subscription is cancelled.
+type Dunning struct {
+	RetryAfter  []time.Duration
+	GracePeriod time.Duration
+}
+
+func DefaultDunning() Dunning {
+	return Dunning{
+		RetryAfter:  []time.Duration{24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour},
+		GracePeriod: 5 * 24 * time.Hour,
+	}
+}
+
+// Scheduler bills subscriptions when Run is called. It does not start any
+// timers itself; call Run from a ticker or cron job.
+type Scheduler struct {
+	mu      sync.Mutex
+	dunning Dunning
+	now     func() time.Time
+	subs    map[string]*Subscription
+	order   []string
+	seq     int
+}
+
+func NewScheduler(dunning Dunning) *Scheduler {
+	return &Scheduler{
+		dunning: dunning,
+		now:     time.Now,
+		subs:    make(map[string]*Subscription),
+	}
+}
+
+func (s *Scheduler) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// Subscribe starts a subscription and charges its first period now. If
+// that charge fails no subscription is created.
+func (s *Scheduler) Subscribe(ctx context.Context, customer string, plan Plan, strategy paymentstrategy.PaymentStrategy) (*Subscription, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	if strategy == nil {
+		return nil, paymentstrategy.ErrInvalidInstrument
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	sub := &Subscription{
+		ID:       s.nextID("sub"),
+		Customer: customer,
+		Plan:     plan,
+		Strategy: strategy,
+		State:    Active,
+		Credit:   money.Zero(plan.Price.Currency()),
+		anchor:   now,
+	}
+	invoice := s.invoice(sub, "first period of "+plan.Name, now, plan.Interval.after(now, 1), plan.Price)
+	if err := s.collect(ctx, sub, invoice); err != nil {
+		return nil, err
+	}
+	sub.PeriodStart, sub.PeriodEnd, sub.periods = invoice.PeriodStart, invoice.PeriodEnd, 1
+	s.subs[sub.ID] = sub
+	s.order = append(s.order, sub.ID)
+	return s.snapshot(sub), nil
+}
+
+// Run renews every subscription whose period has ended and retries
+// overdue invoices that are due another attempt. It returns the invoices
+// it touched. A charge cut short by cancelling ctx leaves its subscription
+// as it was, to be tried again on the next run.
+func (s *Scheduler) Run(ctx context.Context) []*Invoice {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var touched []*Invoice
+	for _, id := range s.order {
+		sub := s.subs[id]
+		for ctx.Err() == nil {
+			invoice := s.step(ctx, sub)
+			if invoice == nil {
+				break
+			}
+			touched = append(touched, invoice)
+		}
+	}
+	return touched
+}
+
+// step does at most one thing to sub and returns the invoice it acted on,
+// or nil when sub needs nothing right now or its charge was interrupted.
+func (s *Scheduler) step(ctx context.Context, sub *Subscription) *Invoice {
+	now := s.now()
+	switch sub.State {
+	case Cancelled:
+		return nil
+	case PastDue, Suspended:
+		if now.Before(sub.NextAttempt) {
+			if sub.State == PastDue && !now.Before(sub.GraceUntil) {
+				sub.State = Suspended
+			}
+			return nil
+		}
+		if !s.retry(ctx, sub) {
+			return nil
+		}
+		return sub.Invoices[len(sub.Invoices)-1]
+	}
+	if now.Before(sub.PeriodEnd) {
+		return nil
+	}
+	if sub.CancelAtPeriodEnd {
+		sub.State = Cancelled
+		sub.CancelledAt = sub.PeriodEnd
+		return nil
+	}
+	start, end := sub.PeriodEnd, sub.Plan.Interval.after(sub.anchor, sub.periods+1)
+	invoice := s.renewal(sub, start, end)
+	if err := s.collect(ctx, sub, invoice); err != nil {
+		if interrupted(ctx, err) {
+			// The invoice stays open and is charged again on the next run.
+			return nil
+		}
+		s.startDunning(sub, invoice, now)
+		return invoice
+	}
+	sub.PeriodStart, sub.PeriodEnd = start, end
+	sub.periods++
+	return invoice
+}
+
+func (s *Scheduler) startDunning(sub *Subscription, invoice *Invoice, failedAt time.Time) {
+	sub.Overdue = invoice
+	sub.Retries = 0
+	sub.FailedAt = failedAt
+	sub.GraceUntil = failedAt.Add(s.dunning.GracePeriod)
+	sub.State = PastDue
+	if !failedAt.Before(sub.GraceUntil) {
+		sub.State = Suspended
+	}
+	if len(s.dunning.RetryAfter) == 0 {
+		s.giveUp(sub, failedAt)
+		return
+	}
+	sub.NextAttempt = failedAt.Add(s.dunning.RetryAfter[0])
+}
+
+// retry charges the overdue invoice again. It reports false, having
+// changed nothing, when the charge was interrupted.
+func (s *Scheduler) retry(ctx context.Context, sub *Subscription) bool {
+	invoice := sub.Overdue
+	now := s.now()
+	err := s.collect(ctx, sub, invoice)
+	if err != nil && interrupted(ctx, err) {
+		// An interrupted attempt does not count against the customer.
+		return false
+	}
+	sub.Retries++
+	if err == nil {
+		sub.PeriodStart, sub.PeriodEnd = invoice.PeriodStart, invoice.PeriodEnd
+		sub.periods++
+		sub.State = Active
+		sub.Overdue, sub.Retries = nil, 0
+		sub.FailedAt, sub.NextAttempt, sub.GraceUntil = time.Time{}, time.Time{}, time.Time{}
+		return true
+	}
+	if sub.Retries >= len(s.dunning.RetryAfter) {
+		s.giveUp(sub, now)
+		return true
+	}
+	sub.NextAttempt = sub.FailedAt.Add(s.dunning.RetryAfter[sub.Retries])
+	if !sub.NextAttempt.After(now) {
+		sub.NextAttempt = now
+	}
+	if !now.Before(sub.GraceUntil) {
+		sub.State = Suspended
+	}
+	return true
+}
+
+func (s *Scheduler) giveUp(sub *Subscription, at time.Time) {
+	sub.Overdue.Status = InvoiceVoid
+	sub.State = Cancelled
+	sub.CancelledAt = at
+}
+
+// Cancel stops a subscription. With atPeriodEnd the customer keeps the
+// period already paid for and is not charged again; otherwise access ends
+// now. Either way an overdue invoice is voided.
+func (s *Scheduler) Cancel(id string, atPeriodEnd bool) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, err := s.active(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Overdue != nil {
+		sub.Overdue.Status = InvoiceVoid
+		sub.Overdue = nil
+		atPeriodEnd = false
+	}
+	if atPeriodEnd {
+		sub.CancelAtPeriodEnd = true
+		return s.snapshot(sub), nil
+	}
+	now := s.now()
+	sub.State = Cancelled
+	sub.CancelledAt = now
+	if sub.PeriodEnd.After(now) {
+		sub.PeriodEnd = now
+	}
+	return s.snapshot(sub), nil
+}
+
+// ChangePlan moves a subscription to plan straight away. Within the same
+// interval the customer is charged, or credited, the difference in price
+// for the rest of the current period. A change of interval starts a fresh
+// period now, with the unused part of the old period credited against it.
+func (s *Scheduler) ChangePlan(ctx context.Context, id string, plan Plan) (*Invoice, error) {
+	if err := plan.Validate(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, err := s.active(id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.State != Active {
+		return nil, fmt.Errorf("%w: %s is %s", ErrCancelled, id, sub.State)
+	}
+	if !plan.Price.SameCurrency(sub.Plan.Price) {
+		return nil, fmt.Errorf("%w: %s is billed in %s", money.ErrCurrencyMismatch, id, sub.Plan.Price.Currency())
+	}
+	now := s.now()
+	remaining := sub.PeriodEnd.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	total := sub.PeriodEnd.Sub(sub.PeriodStart)
+	unused := prorate(sub.Plan.Price, remaining, total)
+
+	if plan.Interval != sub.Plan.Interval {
+		sub.Credit, _ = sub.Credit.Add(unused)
+		end := plan.Interval.after(now, 1)
+		invoice := s.invoice(sub, fmt.Sprintf("switch from %s to %s", sub.Plan.Name, plan.Name), now, end, plan.Price)
+		if err := s.collect(ctx, sub, invoice); err != nil {
+			sub.Credit, _ = sub.Credit.Sub(unused)
+			invoice.Status = InvoiceVoid
+			return invoice, err
+		}
+		sub.Plan, sub.anchor, sub.periods = plan, now, 1
+		sub.PeriodStart, sub.PeriodEnd = now, end
+		return invoice, nil
+	}
+
+	difference, _ := prorate(plan.Price, remaining, total).Sub(unused)
+	description := fmt.Sprintf("proration from %s to %s", sub.Plan.Name, plan.Name)
+	if !difference.IsPositive() {
+		sub.Credit, _ = sub.Credit.Sub(difference)
+		sub.Plan = plan
+		// A negative invoice is a credit note for the unused difference.
+		invoice := s.invoice(sub, description, now, sub.PeriodEnd, difference)
+		invoice.Status = InvoicePaid
+		return invoice, nil
+	}
+	invoice := s.invoice(sub, description, now, sub.PeriodEnd, difference)
+	if err := s.collect(ctx, sub, invoice); err != nil {
+		invoice.Status = InvoiceVoid
+		return invoice, err
+	}
+	sub.Plan = plan
+	return invoice, nil
+}
+
+func (s *Scheduler) Get(id string) (*Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSubscription, id)
+	}
+	return s.snapshot(sub), nil
+}
+
+func (s *Scheduler) Subscriptions() []*Subscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]*Subscription, 0, len(s.order))
+	for _, id := range s.order {
+		subs = append(subs, s.snapshot(s.subs[id]))
+	}
+	return subs
+}
+
+// collect charges whatever of invoice is not covered by credit. A period
+// is only granted for money in hand, so a charge that would settle later,
+// such as an on-chain payment, is withdrawn and treated as failed.
+func (s *Scheduler) collect(ctx context.Context, sub *Subscription, invoice *Invoice) error {
+	if invoice.Status == InvoiceOpen && invoice.Credit.IsZero() {
+		invoice.Credit = minMoney(sub.Credit, invoice.Amount)
+	}
+	due := invoice.Due()
+	if due.IsPositive() {
+		receipt, err := sub.Strategy.Pay(ctx, due)
+		if receipt != nil {
+			invoice.Attempts = append(invoice.Attempts, receipt)
+		}
+		if err == nil && receipt.Pending() {
+			if err = receipt.CancelPending(); err == nil {
+				err = fmt.Errorf("%s: %w", receipt.Method, paymentstrategy.ErrDeferredSettlement)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	sub.Credit, _ = sub.Credit.Sub(invoice.Credit)
+	invoice.Status = InvoicePaid
+	return nil
+}
+
+// renewal returns the invoice for the period from start to end, reusing
+// the open one left behind by an interrupted run.
+func (s *Scheduler) renewal(sub *Subscription, start, end time.Time) *Invoice {
+	if n := len(sub.Invoices); n > 0 {
+		if last := sub.Invoices[n-1]; last.Status == InvoiceOpen && last.PeriodStart.Equal(start) {
+			return last
+		}
+	}
+	return s.invoice(sub, "renewal of "+sub.Plan.Name, start, end, sub.Plan.Price)
+}
+
+func (s *Scheduler) invoice(sub *Subscription, description string, start, end time.Time, amount money.Money) *Invoice {
+	invoice := &Invoice{
+		ID:          s.nextID("inv"),
+		Description: description,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Amount:      amount,
+		Credit:      money.Zero(amount.Currency()),
+		Status:      InvoiceOpen,
+	}
+	sub.Invoices = append(sub.Invoices, invoice)
+	return invoice
+}
+
+func (s *Scheduler) active(id string) (*Subscription, error) {
+	sub, ok := s.subs[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSubscription, id)
+	}
+	if sub.State == Cancelled {
+		return nil, fmt.Errorf("%w: %s", ErrCancelled, id)
+	}
+	return sub, nil
+}
+
+func (s *Scheduler) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s_%06d", prefix, s.seq)
+}
+
+// snapshot copies sub so callers cannot race the scheduler.
+func (s *Scheduler) snapshot(sub *Subscription) *Subscription {
+	copied := *sub
+	copied.Invoices = make([]*Invoice, 0, len(sub.Invoices))
+	for _, inv := range sub.Invoices {
+		c := *inv
+		c.Attempts = append([]*paymentstrategy.Receipt(nil), inv.Attempts...)
+		copied.Invoices = append(copied.Invoices, &c)
+	}
+	if sub.Overdue != nil {
+		copied.Overdue = copied.Invoices[len(copied.Invoices)-1]
+		for i, inv := range sub.Invoices {
+			if inv == sub.Overdue {
+				copied.Overdue = copied.Invoices[i]
+			}
+		}
+	}
+	return &copied
+}
+
+// prorate returns price scaled by part/whole, to the second.
+// interrupted reports whether err means the charge was cut short, by
+// shutdown for instance, rather than declined.
+func interrupted(ctx context.Context, err error) bool {
+	return paymentstrategy.IsCancelled(err) || ctx.Err() != nil
+}
+
+func prorate(price money.Money, part, whole time.Duration) money.Money {
+	if whole <= 0 {
+		return money.Zero(price.Currency())
+	}
+	return price.Scale(int64(part/time.Second), int64(whole/time.Second))
+}
+
+func minMoney(a, b money.Money) money.Money {
+	if cmp, _ := a.Cmp(b); cmp < 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,102 @@
+package subscription
+
+import (
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"time"
+)
+
+type State string
+
+const (
+	Active State = "active"
+	// PastDue subscriptions have a failed renewal being retried but are
+	// still within their grace period, so the customer keeps access.
+	PastDue State = "past due"
+	// Suspended subscriptions are still being retried but their grace
+	// period is over.
+	Suspended State = "suspended"
+	Cancelled State = "cancelled"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceOpen InvoiceStatus = "open"
+	InvoicePaid InvoiceStatus = "paid"
+	InvoiceVoid InvoiceStatus = "void"
+)
+
+type Invoice struct {
+	ID          string
+	Description string
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Amount is what the plan charges for the period; Credit is the part
+	// covered by credit left over from a downgrade, and the strategy is
+	// charged the difference.
+	Amount   money.Money
+	Credit   money.Money
+	Status   InvoiceStatus
+	Attempts []*paymentstrategy.Receipt
+}
+
+func (i *Invoice) Due() money.Money {
+	due, _ := i.Amount.Sub(i.Credit)
+	return due
+}
+
+func (i *Invoice) String() string {
+	return fmt.Sprintf("[%s] %s %s %s (%s - %s), %d attempt(s)", i.Status, i.ID, i.Description, i.Due(), i.PeriodStart.Format("2006-01-02"), i.PeriodEnd.Format("2006-01-02"), len(i.Attempts))
+}
+
+// Subscription is a customer's recurring charge on a stored strategy.
+type Subscription struct {
+	ID       string
+	Customer string
+	Plan     Plan
+	Strategy paymentstrategy.PaymentStrategy
+	State    State
+
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// CancelAtPeriodEnd stops the subscription at its next renewal instead
+	// of charging for another period.
+	CancelAtPeriodEnd bool
+	CancelledAt       time.Time
+	// Credit is owed to the customer from a downgrade and is used up by
+	// later invoices before the strategy is charged.
+	Credit money.Money
+
+	// Dunning: the failed invoice, when it first failed, how many retries
+	// it has had, when the next one is due and when access lapses.
+	Overdue     *Invoice
+	FailedAt    time.Time
+	Retries     int
+	NextAttempt time.Time
+	GraceUntil  time.Time
+
+	Invoices []*Invoice
+
+	anchor  time.Time
+	periods int
+}
+
+// Entitled reports whether the customer should have access at now.
+func (s *Subscription) Entitled(now time.Time) bool {
+	switch s.State {
+	case Active:
+		return true
+	case PastDue:
+		return now.Before(s.GraceUntil)
+	case Cancelled:
+		// Cancelling at period end keeps the paid-for period.
+		return now.Before(s.PeriodEnd)
+	}
+	return false
+}
+
+func (s *Subscription) String() string {
+	return fmt.Sprintf("[%s] %s %s on %s (%s %s), period ends %s", s.State, s.ID, s.Customer, s.Plan.Name, s.Plan.Price, s.Plan.Interval, s.PeriodEnd.Format("2006-01-02"))
+}
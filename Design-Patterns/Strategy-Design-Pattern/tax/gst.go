@@ -0,0 +1,45 @@
+package tax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GST applies Indian GST slabs by product class. Sales within the seller's
+// state are split into CGST and SGST; sales to other states carry IGST.
+type GST struct {
+	SellerState string
+	Slabs       map[string]float64
+	DefaultSlab float64
+}
+
+func NewGST(sellerState string) *GST {
+	return &GST{
+		SellerState: sellerState,
+		Slabs: map[string]float64{
+			"exempt":    0,
+			"essential": 5,
+			"reduced":   12,
+			"standard":  18,
+			"luxury":    28,
+		},
+		DefaultSlab: 18,
+	}
+}
+
+func (g *GST) Calculate(line Line, region Region) (LineTax, error) {
+	rate, ok := g.Slabs[line.Class]
+	if !ok {
+		rate = g.DefaultSlab
+	}
+	label := fmt.Sprintf("IGST %g%%", rate)
+	if strings.EqualFold(region.State, g.SellerState) {
+		label = fmt.Sprintf("CGST %g%% + SGST %g%%", rate/2, rate/2)
+	}
+	return LineTax{
+		SKU:    line.SKU,
+		Label:  label,
+		Rate:   rate,
+		Amount: exclusive(line.Amount, rate),
+	}, nil
+}
@@ -0,0 +1,104 @@
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"strategy-design/money"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNoCalculator = errors.New("no tax calculator for region")
+	ErrUnknownRate  = errors.New("no tax rate for region")
+)
+
+// Region is where the order ships to: an ISO 3166 country code and, where
+// tax depends on it, a state or province code.
+type Region struct {
+	Country string
+	State   string
+}
+
+func (r Region) String() string {
+	if r.State == "" {
+		return r.Country
+	}
+	return r.Country + "-" + r.State
+}
+
+// Line is the taxable base of one cart line, after discounts.
+type Line struct {
+	SKU    string
+	Class  string
+	Amount money.Money
+}
+
+// LineTax is the tax owed on one line. Inclusive taxes are already part of
+// the line amount and must not be added to the total again.
+type LineTax struct {
+	SKU       string
+	Label     string
+	Rate      float64
+	Amount    money.Money
+	Inclusive bool
+}
+
+type Calculator interface {
+	Calculate(line Line, region Region) (LineTax, error)
+}
+
+// Selector picks the calculator for a shipping region by country.
+type Selector struct {
+	mu          sync.RWMutex
+	calculators map[string]Calculator
+}
+
+func NewSelector() *Selector {
+	return &Selector{
+		calculators: make(map[string]Calculator),
+	}
+}
+
+func (s *Selector) Register(country string, calculator Calculator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calculators[strings.ToUpper(country)] = calculator
+}
+
+func (s *Selector) For(region Region) (Calculator, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	calculator, ok := s.calculators[strings.ToUpper(region.Country)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoCalculator, region)
+	}
+	return calculator, nil
+}
+
+// Calculate taxes every line for region.
+func (s *Selector) Calculate(lines []Line, region Region) ([]LineTax, error) {
+	calculator, err := s.For(region)
+	if err != nil {
+		return nil, err
+	}
+	taxes := make([]LineTax, 0, len(lines))
+	for _, line := range lines {
+		t, err := calculator.Calculate(line, region)
+		if err != nil {
+			return nil, err
+		}
+		taxes = append(taxes, t)
+	}
+	return taxes, nil
+}
+
+// exclusive returns percent of amount, rounded to the minor unit.
+func exclusive(amount money.Money, percent float64) money.Money {
+	return amount.ScaleFloat(percent / 100)
+}
+
+// inclusive returns the tax contained in a gross amount at percent.
+func inclusive(amount money.Money, percent float64) money.Money {
+	return amount.ScaleFloat(percent / (100 + percent))
+}
@@ -0,0 +1,47 @@
+package tax
+
+import (
+	"fmt"
+	"strings"
+)
+
+// USSalesTax charges the destination state's sales tax on every class not
+// listed as exempt.
+type USSalesTax struct {
+	StateRates    map[string]float64
+	ExemptClasses map[string]bool
+}
+
+func NewUSSalesTax() *USSalesTax {
+	return &USSalesTax{
+		StateRates: map[string]float64{
+			"CA": 7.25,
+			"NY": 4,
+			"TX": 6.25,
+			"WA": 6.5,
+			"FL": 6,
+			"OR": 0,
+		},
+		ExemptClasses: map[string]bool{
+			"exempt":    true,
+			"groceries": true,
+		},
+	}
+}
+
+func (u *USSalesTax) Calculate(line Line, region Region) (LineTax, error) {
+	state := strings.ToUpper(region.State)
+	rate, ok := u.StateRates[state]
+	if !ok {
+		return LineTax{}, fmt.Errorf("%w: %s", ErrUnknownRate, region)
+	}
+	if u.ExemptClasses[line.Class] {
+		rate = 0
+	}
+	return LineTax{
+		SKU:    line.SKU,
+		Label:  fmt.Sprintf("%s sales tax %g%%", state, rate),
+		Rate:   rate,
+		Amount: exclusive(line.Amount, rate),
+	}, nil
+}
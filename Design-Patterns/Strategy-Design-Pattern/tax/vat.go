@@ -0,0 +1,40 @@
+package tax
+
+import "fmt"
+
+// VAT applies a standard rate with optional reduced rates per class. With
+// Inclusive set, prices already contain VAT and it is only extracted for
+// display.
+type VAT struct {
+	StandardRate float64
+	ReducedRates map[string]float64
+	Inclusive    bool
+}
+
+func NewVAT(standardRate float64, inclusive bool) *VAT {
+	return &VAT{
+		StandardRate: standardRate,
+		ReducedRates: make(map[string]float64),
+		Inclusive:    inclusive,
+	}
+}
+
+func (v *VAT) Calculate(line Line, region Region) (LineTax, error) {
+	rate, ok := v.ReducedRates[line.Class]
+	if !ok {
+		rate = v.StandardRate
+	}
+	t := LineTax{
+		SKU:       line.SKU,
+		Label:     fmt.Sprintf("VAT %g%%", rate),
+		Rate:      rate,
+		Inclusive: v.Inclusive,
+	}
+	if v.Inclusive {
+		t.Label += " incl."
+		t.Amount = inclusive(line.Amount, rate)
+	} else {
+		t.Amount = exclusive(line.Amount, rate)
+	}
+	return t, nil
+}
@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+func (w *Wallet) Authorizations() *paymentstrategy.AuthorizationBook {
+	return w.auths
+}
+
+// Authorize puts a hold on the wallet balance, so the funds cannot be
+// spent elsewhere until the authorization is captured, voided or expires.
+func (w *Wallet) Authorize(ctx context.Context, amount money.Money) (*paymentstrategy.Authorization, error) {
+	if w.store == nil || w.customer == "" {
+		return nil, paymentstrategy.AuthorizationFailed(Method, paymentstrategy.ErrInvalidInstrument)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return nil, paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	// The hold lapses with the authorization, so its funds come back even
+	// if nobody captures or voids it.
+	auth := w.auths.Open(Method, w.customer, amount, "")
+	holdID, err := w.store.HoldUntil(w.customer, amount, auth.ID, auth.ExpiresAt)
+	if err != nil {
+		w.auths.Void(auth.ID)
+		return nil, paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	w.auths.SetReference(auth.ID, holdID)
+	auth.Reference = holdID
+	fmt.Printf("Authorized %s on Wallet: %s\n", amount, w.customer)
+	return auth, nil
+}
+
+// Capture spends amount out of the hold and releases the rest.
+func (w *Wallet) Capture(ctx context.Context, auth *paymentstrategy.Authorization, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	receipt.Instrument = w.customer
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	held, err := w.auths.Capture(auth.ID, amount)
+	if err != nil {
+		return receipt.Fail(err)
+	}
+	if _, err := w.store.CaptureHold(w.customer, held.Reference, amount); err != nil {
+		w.auths.Reopen(auth.ID)
+		return receipt.Fail(err)
+	}
+	receipt.Fee = w.fees.Fee(amount)
+	fmt.Printf("Captured %s of %s on Wallet: %s\n", amount, auth.Amount, w.customer)
+	w.refunds.Record(receipt)
+	return receipt, nil
+}
+
+func (w *Wallet) Void(ctx context.Context, auth *paymentstrategy.Authorization) error {
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	voided, err := w.auths.Void(auth.ID)
+	if err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	if _, err := w.store.Release(w.customer, voided.Reference); err != nil {
+		return paymentstrategy.AuthorizationFailed(Method, err)
+	}
+	fmt.Printf("Voided %s on Wallet: %s\n", auth.Amount, w.customer)
+	return nil
+}
@@ -0,0 +1,36 @@
+package wallet
+
+import (
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+// DefaultFees makes wallet payments free up to a per-payment limit.
+var DefaultFees = paymentstrategy.FeeModel{
+	{Currency: money.USD, MaxAmount: money.MustParse("2000.00", money.USD)},
+	{Currency: money.INR, MaxAmount: money.MustParse("200000.00", money.INR)},
+}
+
+func (w *Wallet) Fees() paymentstrategy.FeeModel {
+	return w.fees
+}
+
+func (w *Wallet) SetFees(fees paymentstrategy.FeeModel) {
+	w.fees = fees
+}
+
+// CanPay checks the customer's available balance, so a selector does not
+// pick the wallet for more than it holds.
+func (w *Wallet) CanPay(amount money.Money) error {
+	available, _, err := w.store.Balance(w.customer)
+	if err != nil {
+		return err
+	}
+	if cmp, err := available.Cmp(amount); err != nil {
+		return err
+	} else if cmp < 0 {
+		return fmt.Errorf("%w: %s available", paymentstrategy.ErrInsufficientFunds, available)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package wallet
+
+import paymentstrategy "strategy-design/payment-strategy"
+
+// Spec: wallet:customer=... (balances live in DefaultStore)
+func init() {
+	paymentstrategy.Register(Method, func(cfg paymentstrategy.Config) (paymentstrategy.PaymentStrategy, error) {
+		customer, err := cfg.Require("customer")
+		if err != nil {
+			return nil, err
+		}
+		return NewWallet(DefaultStore, customer), nil
+	})
+}
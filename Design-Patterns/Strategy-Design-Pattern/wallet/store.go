@@ -0,0 +1,324 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+	"sync"
+	"time"
+)
+
+var (
+	ErrUnknownWallet = errors.New("wallet not found")
+	ErrUnknownHold   = errors.New("hold not found")
+)
+
+type EntryKind string
+
+const (
+	TopUp   EntryKind = "top-up"
+	Debit   EntryKind = "debit"
+	Credit  EntryKind = "credit"
+	Hold    EntryKind = "hold"
+	Release EntryKind = "release"
+	Capture EntryKind = "capture"
+	// Expire is a hold released because it lapsed before it was captured.
+	Expire EntryKind = "expire"
+)
+
+// Entry is one line of a wallet's history. Available and Held are the
+// balances after the entry was applied. Entries for holds carry the hold
+// ID alongside the reference the caller gave when placing the hold.
+type Entry struct {
+	ID        string
+	Customer  string
+	Kind      EntryKind
+	Amount    money.Money
+	Available money.Money
+	Held      money.Money
+	Reference string
+	HoldID    string
+	Timestamp time.Time
+}
+
+func (e Entry) String() string {
+	s := fmt.Sprintf("%s %s %s (%s)", e.ID, e.Kind, e.Amount, e.Reference)
+	if e.HoldID != "" {
+		s += " " + e.HoldID
+	}
+	return s
+}
+
+type account struct {
+	currency  money.Currency
+	available int64
+	holds     map[string]hold
+	history   []Entry
+}
+
+type hold struct {
+	amount    int64
+	reference string
+	expires   time.Time
+}
+
+func (a *account) held() int64 {
+	var total int64
+	for _, h := range a.holds {
+		total += h.amount
+	}
+	return total
+}
+
+// Store keeps per-customer balances. All operations on it are atomic, so
+// concurrent checkouts against the same wallet cannot overspend it.
+type Store struct {
+	mu       sync.Mutex
+	accounts map[string]*account
+	sequence int
+	now      func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		accounts: make(map[string]*account),
+		now:      time.Now,
+	}
+}
+
+// SetClock replaces the time source used for entries and hold expiry.
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// TopUp adds funds, opening the wallet in amount's currency if needed.
+func (s *Store) TopUp(customer string, amount money.Money, reference string) (Entry, error) {
+	if !amount.IsPositive() {
+		return Entry{}, paymentstrategy.ErrInvalidAmount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.account(customer)
+	if err != nil {
+		a = &account{currency: amount.Currency(), holds: make(map[string]hold)}
+		s.accounts[customer] = a
+	}
+	if err := a.sameCurrency(amount); err != nil {
+		return Entry{}, err
+	}
+	a.available += amount.Amount()
+	return s.record(customer, a, TopUp, amount, reference), nil
+}
+
+func (s *Store) Debit(customer string, amount money.Money, reference string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.spendable(customer, amount)
+	if err != nil {
+		return Entry{}, err
+	}
+	a.available -= amount.Amount()
+	return s.record(customer, a, Debit, amount, reference), nil
+}
+
+// Credit returns money to the wallet, e.g. for a refund.
+func (s *Store) Credit(customer string, amount money.Money, reference string) (Entry, error) {
+	if !amount.IsPositive() {
+		return Entry{}, paymentstrategy.ErrInvalidAmount
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.account(customer)
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := a.sameCurrency(amount); err != nil {
+		return Entry{}, err
+	}
+	a.available += amount.Amount()
+	return s.record(customer, a, Credit, amount, reference), nil
+}
+
+// Hold sets funds aside so they cannot be spent elsewhere until the hold
+// is captured or released. It returns the hold ID; reference is kept on
+// every history entry for the hold.
+func (s *Store) Hold(customer string, amount money.Money, reference string) (string, error) {
+	return s.HoldUntil(customer, amount, reference, time.Time{})
+}
+
+// HoldUntil is Hold for a hold that lapses at expires: from then on its
+// funds are available again without anyone releasing it. A zero expires
+// never lapses.
+func (s *Store) HoldUntil(customer string, amount money.Money, reference string, expires time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.spendable(customer, amount)
+	if err != nil {
+		return "", err
+	}
+	a.available -= amount.Amount()
+	s.sequence++
+	id := fmt.Sprintf("hold_%06d", s.sequence)
+	h := hold{amount: amount.Amount(), reference: reference, expires: expires}
+	a.holds[id] = h
+	s.recordHold(customer, a, Hold, amount, id, h)
+	return id, nil
+}
+
+func (s *Store) Release(customer, holdID string) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, h, err := s.hold(customer, holdID)
+	if err != nil {
+		return Entry{}, err
+	}
+	delete(a.holds, holdID)
+	a.available += h.amount
+	return s.recordHold(customer, a, Release, money.New(h.amount, a.currency), holdID, h), nil
+}
+
+// CaptureHold spends amount out of a hold and releases the rest of it.
+func (s *Store) CaptureHold(customer, holdID string, amount money.Money) (Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, h, err := s.hold(customer, holdID)
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := a.sameCurrency(amount); err != nil {
+		return Entry{}, err
+	}
+	if !amount.IsPositive() || amount.Amount() > h.amount {
+		return Entry{}, fmt.Errorf("%w: capture %s from hold of %s", paymentstrategy.ErrInvalidAmount, amount, money.New(h.amount, a.currency))
+	}
+	delete(a.holds, holdID)
+	a.available += h.amount - amount.Amount()
+	return s.recordHold(customer, a, Capture, amount, holdID, h), nil
+}
+
+func (s *Store) Balance(customer string) (available, held money.Money, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.account(customer)
+	if err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	return money.New(a.available, a.currency), money.New(a.held(), a.currency), nil
+}
+
+// History returns the customer's entries, oldest first. With kinds given,
+// only entries of those kinds are returned.
+func (s *Store) History(customer string, kinds ...EntryKind) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.account(customer)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(a.history))
+	for _, e := range a.history {
+		if len(kinds) == 0 || contains(kinds, e.Kind) {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// account looks up a wallet, first releasing any holds that have lapsed.
+func (s *Store) account(customer string) (*account, error) {
+	a, ok := s.accounts[customer]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWallet, customer)
+	}
+	s.expireHolds(customer, a)
+	return a, nil
+}
+
+func (s *Store) expireHolds(customer string, a *account) {
+	now := s.now()
+	var lapsed []string
+	for id, h := range a.holds {
+		if !h.expires.IsZero() && !now.Before(h.expires) {
+			lapsed = append(lapsed, id)
+		}
+	}
+	sort.Strings(lapsed)
+	for _, id := range lapsed {
+		h := a.holds[id]
+		delete(a.holds, id)
+		a.available += h.amount
+		s.recordHold(customer, a, Expire, money.New(h.amount, a.currency), id, h)
+	}
+}
+
+func (s *Store) spendable(customer string, amount money.Money) (*account, error) {
+	if !amount.IsPositive() {
+		return nil, paymentstrategy.ErrInvalidAmount
+	}
+	a, err := s.account(customer)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.sameCurrency(amount); err != nil {
+		return nil, err
+	}
+	if a.available < amount.Amount() {
+		return nil, fmt.Errorf("%w: %s available, %s needed", paymentstrategy.ErrInsufficientFunds, money.New(a.available, a.currency), amount)
+	}
+	return a, nil
+}
+
+func (s *Store) hold(customer, holdID string) (*account, hold, error) {
+	a, err := s.account(customer)
+	if err != nil {
+		return nil, hold{}, err
+	}
+	h, ok := a.holds[holdID]
+	if !ok {
+		return nil, hold{}, fmt.Errorf("%w: %s", ErrUnknownHold, holdID)
+	}
+	return a, h, nil
+}
+
+func (s *Store) record(customer string, a *account, kind EntryKind, amount money.Money, reference string) Entry {
+	s.sequence++
+	e := Entry{
+		ID:        fmt.Sprintf("wlt_%06d", s.sequence),
+		Customer:  customer,
+		Kind:      kind,
+		Amount:    amount,
+		Available: money.New(a.available, a.currency),
+		Held:      money.New(a.held(), a.currency),
+		Reference: reference,
+		Timestamp: s.now(),
+	}
+	a.history = append(a.history, e)
+	return e
+}
+
+func (s *Store) recordHold(customer string, a *account, kind EntryKind, amount money.Money, holdID string, h hold) Entry {
+	s.record(customer, a, kind, amount, h.reference)
+	e := &a.history[len(a.history)-1]
+	e.HoldID = holdID
+	return *e
+}
+
+func (a *account) sameCurrency(amount money.Money) error {
+	if amount.Currency() != a.currency {
+		return fmt.Errorf("%w: wallet is in %s", money.ErrCurrencyMismatch, a.currency)
+	}
+	return nil
+}
+
+func contains(kinds []EntryKind, kind EntryKind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"strategy-design/money"
+	paymentstrategy "strategy-design/payment-strategy"
+)
+
+const Method = "wallet"
+
+// DefaultStore backs wallets built through the strategy registry.
+var DefaultStore = NewStore()
+
+type Wallet struct {
+	store    *Store
+	customer string
+	refunds  *paymentstrategy.RefundBook
+	auths    *paymentstrategy.AuthorizationBook
+	fees     paymentstrategy.FeeModel
+}
+
+func NewWallet(store *Store, customer string) *Wallet {
+	return &Wallet{
+		store:    store,
+		customer: customer,
+		refunds:  paymentstrategy.NewRefundBook(),
+		auths:    paymentstrategy.NewAuthorizationBook(paymentstrategy.DefaultAuthorizationTTL),
+		fees:     DefaultFees,
+	}
+}
+
+func (w *Wallet) Identify() paymentstrategy.Instrument {
+	return paymentstrategy.Instrument{Method: Method, ID: w.customer, Display: w.customer}
+}
+
+func (w *Wallet) Pay(ctx context.Context, amount money.Money) (*paymentstrategy.Receipt, error) {
+	receipt := paymentstrategy.NewReceipt(Method, amount)
+	receipt.Instrument = w.customer
+	if w.store == nil || w.customer == "" {
+		return receipt.Fail(paymentstrategy.ErrInvalidInstrument)
+	}
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return receipt.Fail(err)
+	}
+	if _, err := w.store.Debit(w.customer, amount, receipt.TransactionID); err != nil {
+		return receipt.Fail(err)
+	}
+	receipt.Fee = w.fees.Fee(amount)
+	fmt.Printf("Paid %s using Wallet: %s\n", amount, w.customer)
+	w.refunds.Record(receipt)
+	return receipt, nil
+}
+
+func (w *Wallet) Refund(ctx context.Context, original *paymentstrategy.Receipt, amount money.Money) (*paymentstrategy.Receipt, error) {
+	refund := paymentstrategy.NewRefundReceipt(original, amount)
+	if err := paymentstrategy.Cancelled(ctx); err != nil {
+		return refund.Fail(err)
+	}
+	if err := w.refunds.Reserve(original, amount); err != nil {
+		return refund.Fail(err)
+	}
+	if _, err := w.store.Credit(w.customer, amount, refund.TransactionID); err != nil {
+		w.refunds.Release(original, amount)
+		return refund.Fail(err)
+	}
+	fmt.Printf("Refunded %s to Wallet: %s\n", amount, w.customer)
+	return refund, nil
+}
+
+// Settlements reports every charge made to this wallet and how much of each
+// has been refunded.
+func (w *Wallet) Settlements() []paymentstrategy.Settlement {
+	return w.refunds.Settlements()
+}